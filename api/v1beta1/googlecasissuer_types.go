@@ -17,12 +17,14 @@ limitations under the License.
 package v1beta1
 
 import (
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/cert-manager/issuer-lib/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GoogleCASIssuerSpec defines the desired state of GoogleCASIssuer
+// +kubebuilder:validation:XValidation:rule="!(has(self.credentials) && has(self.workloadIdentityFederation))",message="credentials and workloadIdentityFederation are mutually exclusive"
 type GoogleCASIssuerSpec struct {
 	// Project is the Google Cloud Project ID
 	Project string `json:"project,omitempty"`
@@ -39,21 +41,303 @@ type GoogleCASIssuerSpec struct {
 	// +optional
 	CertificateAuthorityId string `json:"certificateAuthorityId,omitempty"`
 
+	// FailoverTargets are CA pools to issue certificates from, in order, when
+	// issuing from the CA pool above fails with an error that is retried,
+	// such as an outage or exhausted quota. The issuer is Ready as long as
+	// one of its pools can issue certificates, and reports the others in its
+	// Degraded condition
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=atomic
+	// +optional
+	FailoverTargets []CAPoolTarget `json:"failoverTargets,omitempty"`
+
 	// Credentials is a reference to a Kubernetes Secret Key that contains Google Service Account Credentials
 	// +optional
 	Credentials cmmetav1.SecretKeySelector `json:"credentials,omitzero"`
 
+	// WorkloadIdentityFederation authenticates the issuer as a federated
+	// identity, by exchanging a token for a Kubernetes ServiceAccount through
+	// the Google Security Token Service. Mutually exclusive with Credentials
+	// +optional
+	WorkloadIdentityFederation *WorkloadIdentityFederationConfig `json:"workloadIdentityFederation,omitempty"`
+
+	// Impersonate makes the issuer act as another Google Service Account,
+	// using either Credentials or the controller's ambient credentials to
+	// obtain short-lived tokens for it
+	// +optional
+	Impersonate *ImpersonationConfig `json:"impersonate,omitempty"`
+
 	// CertificateTemplate is specific certificate template to
 	// use. Omit to not specify a template
 	// +optional
 	CertificateTemplate string `json:"certificateTemplate,omitempty"`
 
+	// AllowedCertificateTemplates are the certificate templates that
+	// CertificateRequests may select instead of CertificateTemplate, with the
+	// cas-issuer.jetstack.io/certificate-template annotation. Requests for
+	// other templates fail. Omit to not let requests select a template
+	// +listType=set
+	// +optional
+	AllowedCertificateTemplates []string `json:"allowedCertificateTemplates,omitempty"`
+
+	// SubjectMode controls how CAS sets the subject of issued certificates.
+	// Possible values: "Default", "RDNSequence", "ReflectedSPIFFE".
+	// "Default": the subject is taken from the CSR.
+	// "RDNSequence": the subject is taken from the CSR, preserving the order and encoding of its relative distinguished names.
+	// "ReflectedSPIFFE": the certificate gets a single SPIFFE ID URI SAN derived from the issuer's Google identity, and no subject.
+	// The CSR must then request exactly one SPIFFE ID URI SAN, and the issuer needs privateca.certificates.createForSelf.
+	// Omit to use the CAS default
+	// +optional
+	SubjectMode SubjectMode `json:"subjectMode,omitempty"`
+
+	// CertificateLabels are set on every CAS certificate the issuer issues,
+	// e.g. to attribute certificates to clusters and workloads in billing and
+	// audit queries. Values are Go templates that can refer to the
+	// controller's cluster name as .ClusterName, and to the namespace, name
+	// and annotations of the request as .Namespace, .Name and .Annotations.
+	// Rendered values are lowercased, characters CAS doesn't allow in label
+	// values are replaced with "_", and they are truncated to 63 characters
+	// +kubebuilder:validation:MaxProperties=64
+	// +optional
+	CertificateLabels map[string]string `json:"certificateLabels,omitempty"`
+
 	// CAFetchMode controls how the CA certificate chain is fetched and constructed.
 	// Possible values: "CA" (default), "PoolCAs".
 	// "CA": ca.crt contains root CA certificate of the Certificate Authority Service CA that has issued the certificate.
 	// "PoolCAs": ca.crt contains all root CA certificates of all ENABLED, DISABLED, or STAGED Certificate Authority Service CA Pool CAs that are not expired.
 	// +optional
 	CAFetchMode CAFetchMode `json:"caFetchMode,omitempty"`
+
+	// Policy restricts the certificates the issuer requests from CAS, on top
+	// of the issuance policies of the CA pool and certificate template.
+	// Requests that violate it fail without being sent to CAS. Omit to send
+	// every request to CAS
+	// +optional
+	Policy *IssuancePolicy `json:"policy,omitempty"`
+
+	// Revocation revokes the CAS certificates issued for CertificateRequests
+	// once they are retired. Omit to never revoke certificates
+	// +optional
+	Revocation *RevocationPolicy `json:"revocation,omitempty"`
+
+	// TrustBundle periodically publishes the unexpired root certificates of
+	// the issuer's CA pools into a ConfigMap, so that workloads can trust
+	// them without mounting a certificate Secret. Omit to not publish them
+	// +optional
+	TrustBundle *TrustBundle `json:"trustBundle,omitempty"`
+}
+
+// TrustBundle configures the ConfigMap the root certificates of an issuer's
+// CA pools are published to.
+type TrustBundle struct {
+	// ConfigMapName is the name of the ConfigMap. It is created in the
+	// namespace of a GoogleCASIssuer, or in the cluster resource namespace
+	// of a GoogleCASClusterIssuer, and is owned by the issuer
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+
+	// Key is the ConfigMap key the PEM encoded root certificates are written
+	// to. Defaults to ca.crt
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// RefreshInterval is how often the root certificates are fetched from
+	// CAS. Defaults to 1h, and must be at least 1m
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// TrustManagerNamespace publishes the ConfigMap of a
+	// GoogleCASClusterIssuer into this namespace, which should be the trust
+	// namespace of trust-manager, instead of the cluster resource namespace.
+	// The ConfigMap is labelled cas-issuer.jetstack.io/trust-bundle=true, so
+	// that trust-manager Bundles can select it as a source. Only
+	// GoogleCASClusterIssuers may set it
+	// +optional
+	TrustManagerNamespace string `json:"trustManagerNamespace,omitempty"`
+}
+
+// CAPoolTarget is a CA pool an issuer can issue certificates from.
+type CAPoolTarget struct {
+	// Project is the Google Cloud Project ID
+	// +kubebuilder:validation:MinLength=1
+	Project string `json:"project"`
+
+	// Location is the Google Cloud Project Location
+	// +kubebuilder:validation:MinLength=1
+	Location string `json:"location"`
+
+	// CaPoolId is the id of the CA pool to issue certificates from
+	// +kubebuilder:validation:MinLength=1
+	CaPoolId string `json:"caPoolId"`
+
+	// CertificateAuthorityId is specific certificate authority to
+	// use to sign. Omit in order to load balance across all CAs
+	// in the pool
+	// +optional
+	CertificateAuthorityId string `json:"certificateAuthorityId,omitempty"`
+}
+
+// IssuancePolicy restricts the certificates an issuer requests. Omitted
+// restrictions allow anything, except that CA certificates must be allowed
+// with AllowCA.
+type IssuancePolicy struct {
+	// AllowedDNSNames are patterns of the DNS SANs and common name requests
+	// may ask for. A "*" matches one or more characters within a single label,
+	// e.g. "*.example.com" matches "www.example.com" but neither "example.com"
+	// nor "a.b.example.com"
+	// +listType=set
+	// +optional
+	AllowedDNSNames []string `json:"allowedDNSNames,omitempty"`
+
+	// AllowedIPRanges are the CIDR ranges the IP SANs of requests must be in
+	// +listType=set
+	// +optional
+	AllowedIPRanges []string `json:"allowedIPRanges,omitempty"`
+
+	// AllowedURIs are patterns of the URI SANs requests may ask for. A "*"
+	// matches one or more characters within a single path segment, e.g.
+	// "spiffe://example.org/ns/*/sa/*"
+	// +listType=set
+	// +optional
+	AllowedURIs []string `json:"allowedURIs,omitempty"`
+
+	// AllowedEmailAddresses are patterns of the email SANs requests may ask
+	// for. A "*" matches one or more characters except "@", e.g.
+	// "*@example.com"
+	// +listType=set
+	// +optional
+	AllowedEmailAddresses []string `json:"allowedEmailAddresses,omitempty"`
+
+	// AllowedPrivateKeys are the key algorithms, and their minimum sizes, that
+	// requests may use
+	// +listType=map
+	// +listMapKey=algorithm
+	// +optional
+	AllowedPrivateKeys []PrivateKeyPolicy `json:"allowedPrivateKeys,omitempty"`
+
+	// MaxDuration is the longest certificate lifetime requests may ask for
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+
+	// AllowedUsages are the key usages requests may ask for, e.g.
+	// "digital signature", "key encipherment" and "server auth"
+	// +listType=set
+	// +optional
+	AllowedUsages []cmapi.KeyUsage `json:"allowedUsages,omitempty"`
+
+	// AllowCA allows requests for CA certificates, which are refused otherwise
+	// +optional
+	AllowCA bool `json:"allowCA,omitempty"`
+}
+
+// PrivateKeyPolicy allows a private key algorithm.
+type PrivateKeyPolicy struct {
+	// Algorithm is the allowed key algorithm
+	// +kubebuilder:validation:Enum=RSA;ECDSA;Ed25519
+	Algorithm cmapi.PrivateKeyAlgorithm `json:"algorithm"`
+
+	// MinSize is the minimum key size in bits, e.g. 3072 for RSA or 384 for
+	// ECDSA. Omit to allow any size. Ed25519 keys have no size
+	// +optional
+	MinSize int `json:"minSize,omitempty"`
+}
+
+// RevocationPolicy configures when the CAS certificates issued for
+// CertificateRequests are revoked.
+type RevocationPolicy struct {
+	// On lists the events that revoke a certificate:
+	// "CertificateRequestDeleted": its CertificateRequest is deleted.
+	// "CertificateDeleted": the Certificate that owns its CertificateRequest is deleted.
+	// "Superseded": the Certificate that owns its CertificateRequest was renewed more than GracePeriod ago.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	On []RevocationTrigger `json:"on"`
+
+	// GracePeriod is how long a superseded certificate stays valid after its
+	// Certificate was renewed, so that workloads can pick up the new one.
+	// Defaults to 1h
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+
+	// Reason is the revocation reason reported by CAS. Defaults to
+	// CessationOfOperation
+	// +optional
+	Reason RevocationReason `json:"reason,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=CertificateRequestDeleted;CertificateDeleted;Superseded
+// RevocationTrigger is an event that revokes a CAS certificate.
+type RevocationTrigger string
+
+const (
+	// RevokeOnCertificateRequestDeleted revokes a certificate when its CertificateRequest is deleted.
+	RevokeOnCertificateRequestDeleted RevocationTrigger = "CertificateRequestDeleted"
+
+	// RevokeOnCertificateDeleted revokes a certificate when the Certificate that owns its CertificateRequest is deleted.
+	RevokeOnCertificateDeleted RevocationTrigger = "CertificateDeleted"
+
+	// RevokeOnSuperseded revokes a certificate once the Certificate that owns its CertificateRequest has been renewed.
+	RevokeOnSuperseded RevocationTrigger = "Superseded"
+)
+
+// +kubebuilder:validation:Enum=Unspecified;KeyCompromise;CertificateAuthorityCompromise;AffiliationChanged;Superseded;CessationOfOperation;CertificateHold;PrivilegeWithdrawn;AttributeAuthorityCompromise
+// RevocationReason is a certificate revocation reason, as defined in RFC 5280.
+type RevocationReason string
+
+const (
+	RevocationReasonUnspecified                    RevocationReason = "Unspecified"
+	RevocationReasonKeyCompromise                  RevocationReason = "KeyCompromise"
+	RevocationReasonCertificateAuthorityCompromise RevocationReason = "CertificateAuthorityCompromise"
+	RevocationReasonAffiliationChanged             RevocationReason = "AffiliationChanged"
+	RevocationReasonSuperseded                     RevocationReason = "Superseded"
+	RevocationReasonCessationOfOperation           RevocationReason = "CessationOfOperation"
+	RevocationReasonCertificateHold                RevocationReason = "CertificateHold"
+	RevocationReasonPrivilegeWithdrawn             RevocationReason = "PrivilegeWithdrawn"
+	RevocationReasonAttributeAuthorityCompromise   RevocationReason = "AttributeAuthorityCompromise"
+)
+
+// WorkloadIdentityFederationConfig configures authentication through a
+// Workload Identity Federation pool provider that trusts the cluster's
+// ServiceAccount token issuer.
+type WorkloadIdentityFederationConfig struct {
+	// ServiceAccountName is the name of the Kubernetes ServiceAccount to request
+	// a token for. It must be in the issuer's namespace, or in the cluster
+	// resource namespace for a GoogleCASClusterIssuer
+	// +kubebuilder:validation:MinLength=1
+	ServiceAccountName string `json:"serviceAccountName"`
+
+	// Audience is the full resource name of the workload identity pool
+	// provider, e.g.
+	// //iam.googleapis.com/projects/PROJECT_NUMBER/locations/global/workloadIdentityPools/POOL_ID/providers/PROVIDER_ID
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+
+	// TokenAudience is the audience of the requested ServiceAccount token. It
+	// must be one of the provider's allowed audiences. Defaults to Audience
+	// with an https: prefix, which is the provider's default allowed audience
+	// +optional
+	TokenAudience string `json:"tokenAudience,omitempty"`
+}
+
+// ImpersonationConfig configures Google Service Account impersonation.
+// The identity the issuer authenticates as needs roles/iam.serviceAccountTokenCreator
+// on the target principal, or on the first delegate if a delegate chain is set.
+type ImpersonationConfig struct {
+	// TargetPrincipal is the email address of the Google Service Account to impersonate
+	// +kubebuilder:validation:MinLength=1
+	TargetPrincipal string `json:"targetPrincipal"`
+
+	// Delegates is the chain of Google Service Account email addresses to
+	// impersonate the target principal through. Each service account must be
+	// allowed to create tokens for the next one in the chain, the last one for
+	// the target principal
+	// +optional
+	Delegates []string `json:"delegates,omitempty"`
+
+	// Scopes are the OAuth scopes of the impersonated tokens. Defaults to
+	// https://www.googleapis.com/auth/cloud-platform
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=CA;PoolCAs
@@ -68,6 +352,23 @@ const (
 	CAFetchModePoolCAs CAFetchMode = "PoolCAs"
 )
 
+// +kubebuilder:validation:Enum=Default;RDNSequence;ReflectedSPIFFE
+// SubjectMode controls how CAS sets the subject of issued certificates.
+type SubjectMode string
+
+const (
+	// SubjectModeDefault takes the subject from the CSR.
+	SubjectModeDefault SubjectMode = "Default"
+
+	// SubjectModeRDNSequence takes the subject from the CSR, preserving the
+	// order and encoding of its relative distinguished names.
+	SubjectModeRDNSequence SubjectMode = "RDNSequence"
+
+	// SubjectModeReflectedSPIFFE issues certificates with a single SPIFFE ID
+	// URI SAN derived from the issuer's Google identity.
+	SubjectModeReflectedSPIFFE SubjectMode = "ReflectedSPIFFE"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:printcolumn:name="ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="reason",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].reason"
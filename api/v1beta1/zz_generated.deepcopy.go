@@ -21,15 +21,32 @@ limitations under the License.
 package v1beta1
 
 import (
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CAPoolTarget) DeepCopyInto(out *CAPoolTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CAPoolTarget.
+func (in *CAPoolTarget) DeepCopy() *CAPoolTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(CAPoolTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GoogleCASClusterIssuer) DeepCopyInto(out *GoogleCASClusterIssuer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -88,7 +105,7 @@ func (in *GoogleCASIssuer) DeepCopyInto(out *GoogleCASIssuer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -145,7 +162,49 @@ func (in *GoogleCASIssuerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GoogleCASIssuerSpec) DeepCopyInto(out *GoogleCASIssuerSpec) {
 	*out = *in
+	if in.FailoverTargets != nil {
+		in, out := &in.FailoverTargets, &out.FailoverTargets
+		*out = make([]CAPoolTarget, len(*in))
+		copy(*out, *in)
+	}
 	out.Credentials = in.Credentials
+	if in.WorkloadIdentityFederation != nil {
+		in, out := &in.WorkloadIdentityFederation, &out.WorkloadIdentityFederation
+		*out = new(WorkloadIdentityFederationConfig)
+		**out = **in
+	}
+	if in.Impersonate != nil {
+		in, out := &in.Impersonate, &out.Impersonate
+		*out = new(ImpersonationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedCertificateTemplates != nil {
+		in, out := &in.AllowedCertificateTemplates, &out.AllowedCertificateTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CertificateLabels != nil {
+		in, out := &in.CertificateLabels, &out.CertificateLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(IssuancePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Revocation != nil {
+		in, out := &in.Revocation, &out.Revocation
+		*out = new(RevocationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustBundle != nil {
+		in, out := &in.TrustBundle, &out.TrustBundle
+		*out = new(TrustBundle)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleCASIssuerSpec.
@@ -157,3 +216,153 @@ func (in *GoogleCASIssuerSpec) DeepCopy() *GoogleCASIssuerSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpersonationConfig) DeepCopyInto(out *ImpersonationConfig) {
+	*out = *in
+	if in.Delegates != nil {
+		in, out := &in.Delegates, &out.Delegates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpersonationConfig.
+func (in *ImpersonationConfig) DeepCopy() *ImpersonationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuancePolicy) DeepCopyInto(out *IssuancePolicy) {
+	*out = *in
+	if in.AllowedDNSNames != nil {
+		in, out := &in.AllowedDNSNames, &out.AllowedDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedIPRanges != nil {
+		in, out := &in.AllowedIPRanges, &out.AllowedIPRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedURIs != nil {
+		in, out := &in.AllowedURIs, &out.AllowedURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedEmailAddresses != nil {
+		in, out := &in.AllowedEmailAddresses, &out.AllowedEmailAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedPrivateKeys != nil {
+		in, out := &in.AllowedPrivateKeys, &out.AllowedPrivateKeys
+		*out = make([]PrivateKeyPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AllowedUsages != nil {
+		in, out := &in.AllowedUsages, &out.AllowedUsages
+		*out = make([]certmanagerv1.KeyUsage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuancePolicy.
+func (in *IssuancePolicy) DeepCopy() *IssuancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateKeyPolicy) DeepCopyInto(out *PrivateKeyPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateKeyPolicy.
+func (in *PrivateKeyPolicy) DeepCopy() *PrivateKeyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateKeyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevocationPolicy) DeepCopyInto(out *RevocationPolicy) {
+	*out = *in
+	if in.On != nil {
+		in, out := &in.On, &out.On
+		*out = make([]RevocationTrigger, len(*in))
+		copy(*out, *in)
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevocationPolicy.
+func (in *RevocationPolicy) DeepCopy() *RevocationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RevocationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustBundle) DeepCopyInto(out *TrustBundle) {
+	*out = *in
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustBundle.
+func (in *TrustBundle) DeepCopy() *TrustBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadIdentityFederationConfig) DeepCopyInto(out *WorkloadIdentityFederationConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadIdentityFederationConfig.
+func (in *WorkloadIdentityFederationConfig) DeepCopy() *WorkloadIdentityFederationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadIdentityFederationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
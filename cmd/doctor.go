@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+	controllers "github.com/cert-manager/google-cas-issuer/pkg/controllers"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [NAME]",
+	Short: "Diagnose why an issuer can't sign certificates",
+	Long: `Diagnose why an issuer can't sign certificates, step by step: its
+credentials, CA pools and their CAs, certificate templates, IAM permissions,
+and a validate-only issuance from each CA pool.
+
+The issuer is the GoogleCASClusterIssuer NAME, or the GoogleCASIssuer NAME in
+--namespace, read from the cluster of the current kubeconfig, or the one in the
+--issuer manifest. Credentials are resolved like the controller does, so run
+the command with the controller's Application Default Credentials to check
+issuers that use them.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	// The flags are bound when the command runs, see signCmd.
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doctor(cmd.Context(), cmd.OutOrStdout(), args)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringP("namespace", "n", "", "Namespace of the GoogleCASIssuer NAME. A GoogleCASClusterIssuer is diagnosed if empty.")
+	doctorCmd.Flags().String("issuer", "", "Path of a GoogleCASIssuer or GoogleCASClusterIssuer manifest to diagnose instead of an issuer in the cluster.")
+	doctorCmd.Flags().StringP("output", "o", "text", "Output format: text or json.")
+
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func doctor(ctx context.Context, stdout io.Writer, args []string) error {
+	if _, _, err := setupLogging(); err != nil {
+		return err
+	}
+	output := viper.GetString("output")
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unknown output format %q, must be text or json", output)
+	}
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := issuersv1beta1.AddToScheme(scheme); err != nil {
+		return err
+	}
+
+	var issuerObj issuerapi.Issuer
+	var kubeClient client.Client
+	switch path := viper.GetString("issuer"); {
+	case path != "" && len(args) > 0:
+		return errors.New("diagnose either an issuer in the cluster or an --issuer manifest, not both")
+	case path != "":
+		var err error
+		if issuerObj, err = readIssuer(path); err != nil {
+			return err
+		}
+		// Credentials Secrets are read from the cluster, if there is one.
+		if config, err := ctrl.GetConfig(); err == nil {
+			kubeClient, _ = client.New(config, client.Options{Scheme: scheme})
+		}
+	case len(args) == 1:
+		config, err := ctrl.GetConfig()
+		if err != nil {
+			return err
+		}
+		if kubeClient, err = client.New(config, client.Options{Scheme: scheme}); err != nil {
+			return err
+		}
+		key := client.ObjectKey{Namespace: viper.GetString("namespace"), Name: args[0]}
+		issuerObj = &issuersv1beta1.GoogleCASClusterIssuer{}
+		if key.Namespace != "" {
+			issuerObj = &issuersv1beta1.GoogleCASIssuer{}
+		}
+		if err := kubeClient.Get(ctx, key, issuerObj); err != nil {
+			return err
+		}
+	default:
+		return errors.New("name the issuer to diagnose, or set --issuer")
+	}
+
+	cas := &controllers.GoogleCAS{
+		Endpoint:    viper.GetString("cas-endpoint"),
+		ClusterName: viper.GetString("cluster-name"),
+	}
+	checks := cas.Diagnose(ctx, kubeClient, issuerObj)
+
+	if output == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(checks); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tTARGET\tSTATUS\tMESSAGE")
+		for _, check := range checks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", check.Name, check.Target, check.Status, check.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status == controllers.DoctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
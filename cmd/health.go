@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	controllers "github.com/cert-manager/google-cas-issuer/pkg/controllers"
+)
+
+// cacheSyncTimeout bounds how long a readiness probe waits for the informer
+// caches to sync.
+const cacheSyncTimeout = time.Second
+
+// addHealthChecks registers the checks served on /healthz and /readyz.
+//
+// Readiness reflects whether the informer caches have synced, whether the
+// replica was asked to stop or lost its leader election lease, and optionally
+// whether the CAS endpoint is reachable.
+// Standby replicas are ready while they wait for the leader election lease:
+// gating readiness on the lease would block the rollout of a single replica,
+// whose new pod can't be elected until the pod it replaces is gone.
+func addHealthChecks(ctx context.Context, mgr ctrl.Manager, cas *controllers.GoogleCAS) error {
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+
+	if err := mgr.AddReadyzCheck("informers", func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), cacheSyncTimeout)
+		defer cancel()
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return errors.New("informer caches have not synced")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.AddReadyzCheck("shutdown", func(*http.Request) error {
+		// ctx is done once the process is asked to stop, and the replica must
+		// not receive webhook traffic while it drains. Losing the leader
+		// election lease doesn't cancel ctx, see leaderStatus.
+		if ctx.Err() != nil {
+			return errors.New("the manager is stopping")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	leader := &leaderStatus{}
+	if err := mgr.Add(leader); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("leader-election", leader.check); err != nil {
+		return err
+	}
+
+	if viper.GetBool("readyz-cas-check") {
+		return mgr.AddReadyzCheck("cas-endpoint", cas.CheckEndpoint)
+	}
+	return nil
+}
+
+// leaderStatus is a runnable that runs while the replica leads, and records
+// when it stopped leading. The manager cancels it once the leader election
+// lease is lost, before it stops serving the probes and exits.
+type leaderStatus struct {
+	stopped atomic.Bool
+}
+
+var _ manager.LeaderElectionRunnable = &leaderStatus{}
+
+func (l *leaderStatus) Start(ctx context.Context) error {
+	<-ctx.Done()
+	l.stopped.Store(true)
+	return nil
+}
+
+func (l *leaderStatus) NeedLeaderElection() bool {
+	return true
+}
+
+// check fails once the replica stopped leading, until the process restarts.
+// Standby replicas that are waiting for the lease pass.
+func (l *leaderStatus) check(*http.Request) error {
+	if l.stopped.Load() {
+		return errors.New("the replica stopped leading")
+	}
+	return nil
+}
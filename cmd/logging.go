@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// maxLogLevel is the highest verbosity SIGUSR1 raises the log level to.
+const maxLogLevel = 10
+
+// setupLogging sets the logger of the controller, and of the Kubernetes
+// libraries it uses, from --log-format and --log-level. It returns the
+// configured verbosity and the level, which can be changed at runtime.
+func setupLogging() (int, zap.AtomicLevel, error) {
+	verbosity, err := strconv.Atoi(viper.GetString("log-level"))
+	if err != nil || verbosity < 0 {
+		return 0, zap.AtomicLevel{}, fmt.Errorf("invalid log level %q, must be a number from 1 to 5", viper.GetString("log-level"))
+	}
+
+	var encoder crzap.Opts
+	switch format := viper.GetString("log-format"); format {
+	case "text":
+		encoder = crzap.ConsoleEncoder()
+	case "json":
+		encoder = crzap.JSONEncoder()
+	default:
+		return 0, zap.AtomicLevel{}, fmt.Errorf("unknown log format %q, must be text or json", format)
+	}
+
+	// logr verbosity n is zap level -n.
+	level := zap.NewAtomicLevelAt(zapcore.Level(-verbosity))
+	log := crzap.New(encoder, crzap.Level(level))
+	ctrl.SetLogger(log)
+	klog.SetLogger(log)
+	return verbosity, level, nil
+}
+
+// handleLogLevelSignals changes the log level at runtime until ctx is done:
+// SIGUSR1 raises the verbosity by one, and SIGUSR2 restores the configured
+// verbosity. Only users who can signal the controller's process, e.g. with an
+// ephemeral debug container, can change it.
+func handleLogLevelSignals(ctx context.Context, level zap.AtomicLevel, verbosity int) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-signals:
+				current := -int(level.Level())
+				next := verbosity
+				if sig == syscall.SIGUSR1 {
+					next = min(current+1, maxLogLevel)
+				}
+				level.SetLevel(zapcore.Level(-next))
+				setupLog.Info("Changed log level", "signal", sig.String(), "from", current, "to", next)
+			}
+		}
+	}()
+}
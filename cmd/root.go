@@ -17,22 +17,24 @@ limitations under the License.
 package cmd
 
 import (
-	"flag"
+	"context"
 	"time"
 
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/klog/v2"
-	"k8s.io/klog/v2/klogr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
 	controllers "github.com/cert-manager/google-cas-issuer/pkg/controllers"
+	"github.com/cert-manager/google-cas-issuer/pkg/webhooks"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 )
@@ -53,22 +55,36 @@ var (
 func init() {
 	// Issuer flags
 	rootCmd.PersistentFlags().String("metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	rootCmd.PersistentFlags().String("health-probe-addr", ":8081", "The address the /healthz and /readyz probe endpoints bind to. Disabled if \"0\".")
+	rootCmd.PersistentFlags().Bool("readyz-cas-check", false, "Report not ready on /readyz while the Google CAS API endpoint doesn't accept connections.")
 	rootCmd.PersistentFlags().Bool("enable-leader-election", false, "Enable leader election for controller manager.")
 	rootCmd.PersistentFlags().String("leader-election-id", "cm-google-cas-issuer", "The ID of the leader election lock that the controller should attempt to acquire.")
 	rootCmd.PersistentFlags().String("cluster-resource-namespace", "cert-manager", "The namespace for secrets in which cluster-scoped resources are found.")
 	rootCmd.PersistentFlags().Bool("disable-approval-check", false, "Don't check whether a CertificateRequest is approved before signing. For compatibility with cert-manager <v1.3.0.")
 	rootCmd.PersistentFlags().Int("max-concurrent-reconciles", defaultMaxConcurrentReconciles, "Maximum number of concurrent reconciliations.")
-
-	rootCmd.PersistentFlags().StringP("log-level", "v", "1", "Log level (1-5).")
+	rootCmd.PersistentFlags().Bool("enable-webhooks", false, "Serve the validating and defaulting webhooks for issuer resources on port 9443.")
+	rootCmd.PersistentFlags().String("cas-endpoint", "", "Address of the Google CAS API, e.g. a Private Service Connect endpoint. Defaults to the public endpoint.")
+	rootCmd.PersistentFlags().String("cluster-name", "", "Name of the cluster, available to the certificateLabels templates of issuers as .ClusterName.")
+	rootCmd.PersistentFlags().Bool("enable-trust-bundles", false, "Publish the trust bundles of issuers with a trustBundle to ConfigMaps.")
+	rootCmd.PersistentFlags().String("trust-bundle-namespace", "", "Only publish trust bundles to ConfigMaps in this namespace, e.g. trust-manager's trust namespace. Any namespace if empty.")
+	rootCmd.PersistentFlags().String("kubelet-serving-issuer", "", "Name of a GoogleCASClusterIssuer that approves and signs kubernetes.io/kubelet-serving CertificateSigningRequests. Disabled if empty.")
+	rootCmd.PersistentFlags().Duration("cas-client-idle-timeout", 10*time.Minute, "How long an unused Google CAS API client is kept open before it is closed.")
+	rootCmd.PersistentFlags().String("tracing-exporter", "none", "Exporter for OpenTelemetry traces of signing requests and issuer checks: none, otlp-grpc or otlp-http.")
+	rootCmd.PersistentFlags().String("tracing-endpoint", "", "Address of the OTLP collector traces are exported to. Defaults to OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, OTEL_EXPORTER_OTLP_ENDPOINT or the exporter's default.")
+	rootCmd.PersistentFlags().Bool("tracing-insecure", false, "Export traces to the OTLP collector without TLS.")
+	rootCmd.PersistentFlags().Float64("tracing-sample-ratio", 1, "Fraction of signing requests and issuer checks that are traced.")
+
+	rootCmd.PersistentFlags().StringP("log-level", "v", "1", "Log level (1-5). SIGUSR1 raises it by one at runtime, SIGUSR2 restores it.")
+	rootCmd.PersistentFlags().String("log-format", "text", "Log format: text or json.")
 
 	viper.BindPFlags(rootCmd.PersistentFlags())
 }
 
 func root() error {
-	klog.InitFlags(nil)
-	log := klogr.New()
-	flag.Set("v", viper.GetString("log-level"))
-	ctrl.SetLogger(log)
+	verbosity, logLevel, err := setupLogging()
+	if err != nil {
+		return err
+	}
 
 	// Add all APIs to scheme
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -93,8 +109,10 @@ func root() error {
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port: 9443,
 		}),
-		LeaderElection:   viper.GetBool("enable-leader-election"),
-		LeaderElectionID: viper.GetString("leader-election-id"),
+		Cache:                  cacheOptions(),
+		HealthProbeBindAddress: viper.GetString("health-probe-addr"),
+		LeaderElection:         viper.GetBool("enable-leader-election"),
+		LeaderElectionID:       viper.GetString("leader-election-id"),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -102,6 +120,21 @@ func root() error {
 	}
 
 	ctx := ctrl.SetupSignalHandler()
+	handleLogLevelSignals(ctx, logLevel, verbosity)
+
+	shutdownTracing, err := setupTracing(ctx)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		return err
+	}
+	defer func() {
+		// ctx is done once the manager stopped, so flush with a fresh one.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			setupLog.Error(err, "failed to flush traces")
+		}
+	}()
 
 	// Read and clamp concurrency
 	maxConcurrentReconciles := max(viper.GetInt("max-concurrent-reconciles"), defaultMaxConcurrentReconciles)
@@ -109,14 +142,41 @@ func root() error {
 	ctrlOpts := controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}
 
 	// Start Controllers
-	if err = (&controllers.GoogleCAS{
-		MaxRetryDuration: 30 * time.Second,
-	}).SetupWithManager(ctx, mgr, ctrlOpts); err != nil {
+	cas := &controllers.GoogleCAS{
+		MaxRetryDuration:  30 * time.Second,
+		ClientIdleTimeout: viper.GetDuration("cas-client-idle-timeout"),
+		Endpoint:          viper.GetString("cas-endpoint"),
+		ClusterName:       viper.GetString("cluster-name"),
+
+		TrustBundles:         viper.GetBool("enable-trust-bundles"),
+		TrustBundleNamespace: viper.GetString("trust-bundle-namespace"),
+	}
+	if err = cas.SetupWithManager(ctx, mgr, ctrlOpts); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GoogleCASIssuer")
 		return err
 	}
+	if issuerName := viper.GetString("kubelet-serving-issuer"); issuerName != "" {
+		if err := (&controllers.KubeletServingSigner{
+			IssuerName: issuerName,
+			CAS:        cas,
+		}).SetupWithManager(mgr, ctrlOpts); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "KubeletServing")
+			return err
+		}
+	}
+	if viper.GetBool("enable-webhooks") {
+		if err := webhooks.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			return err
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err := addHealthChecks(ctx, mgr, cas); err != nil {
+		setupLog.Error(err, "unable to set up health checks")
+		return err
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -124,3 +184,16 @@ func root() error {
 	}
 	return nil
 }
+
+// cacheOptions restricts the ConfigMaps the manager watches to the trust
+// bundle namespace, if there is one, as the controller may only be allowed
+// to access ConfigMaps there.
+func cacheOptions() cache.Options {
+	namespace := viper.GetString("trust-bundle-namespace")
+	if namespace == "" {
+		return cache.Options{}
+	}
+	return cache.Options{ByObject: map[client.Object]cache.ByObject{
+		&corev1.ConfigMap{}: {Namespaces: map[string]cache.Config{namespace: {}}},
+	}}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+	controllers "github.com/cert-manager/google-cas-issuer/pkg/controllers"
+	"github.com/cert-manager/google-cas-issuer/pkg/webhooks"
+)
+
+// signRequestName names the CertificateRequest that the sign command signs,
+// e.g. in the labels of CAS certificates.
+const signRequestName = "google-cas-issuer-sign"
+
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign a certificate signing request with Google CAS, without a cluster",
+	Long: `Sign a certificate signing request with Google CAS, without a cluster, e.g. to
+bootstrap a cluster before cert-manager is installed.
+
+The request is signed like a CertificateRequest for the issuer in --issuer, or
+for the issuer described by the flags that mirror its spec, which override the
+fields of --issuer. The CAS API is called with Application Default Credentials.
+
+The certificate chain and CA are written to tls.crt and ca.crt in --output-dir,
+or printed as a Secret manifest with --secret-name.`,
+	Args: cobra.NoArgs,
+	// The flags are bound when the command runs, so that they don't clash
+	// with flags of the same name on other commands.
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sign(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	signCmd.Flags().String("csr", "", "Path of the PEM encoded certificate signing request to sign.")
+	signCmd.Flags().String("issuer", "", "Path of a GoogleCASIssuer or GoogleCASClusterIssuer manifest to sign with.")
+	signCmd.Flags().String("project", "", "GCP project of the CA pool, see spec.project.")
+	signCmd.Flags().String("location", "", "Location of the CA pool, see spec.location.")
+	signCmd.Flags().String("ca-pool-id", "", "ID of the CA pool, see spec.caPoolId.")
+	signCmd.Flags().String("certificate-authority-id", "", "ID of the CA that issues the certificate, see spec.certificateAuthorityId.")
+	signCmd.Flags().String("certificate-template", "", "Resource name of the certificate template, see spec.certificateTemplate.")
+	signCmd.Flags().String("subject-mode", "", "How CAS sets the subject of the certificate, see spec.subjectMode.")
+	signCmd.Flags().String("ca-fetch-mode", "", "Which CA certificates are written to ca.crt, see spec.caFetchMode.")
+	signCmd.Flags().StringToString("certificate-labels", nil, "Labels of the CAS certificate as key=value pairs, see spec.certificateLabels.")
+	signCmd.Flags().Duration("duration", cmapi.DefaultCertificateDuration, "Requested lifetime of the certificate.")
+	signCmd.Flags().Bool("is-ca", false, "Request a CA certificate.")
+	signCmd.Flags().StringSlice("usages", nil, "Key usages of the certificate, e.g. \"digital signature,server auth\". Defaults to cert-manager's default usages.")
+	signCmd.Flags().String("output-dir", ".", "Directory tls.crt and ca.crt are written to.")
+	signCmd.Flags().String("secret-name", "", "Print a Secret manifest of this name instead of writing files.")
+	signCmd.Flags().String("secret-namespace", "", "Namespace of the Secret manifest.")
+	signCmd.Flags().String("key", "", "Path of the private key of the request, added to the Secret manifest as tls.key.")
+	signCmd.MarkFlagRequired("csr")
+
+	rootCmd.AddCommand(signCmd)
+}
+
+func sign(ctx context.Context, stdout io.Writer) error {
+	if _, _, err := setupLogging(); err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	csr, err := os.ReadFile(viper.GetString("csr"))
+	if err != nil {
+		return err
+	}
+	issuerObj, err := signIssuer()
+	if err != nil {
+		return err
+	}
+
+	var usages []cmapi.KeyUsage
+	for _, usage := range viper.GetStringSlice("usages") {
+		usages = append(usages, cmapi.KeyUsage(usage))
+	}
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: signRequestName,
+			// The UID seeds the CAS certificate ID, so that every run issues
+			// a new certificate.
+			UID: types.UID(uuid.NewString()),
+		},
+		Spec: cmapi.CertificateRequestSpec{
+			Request:  csr,
+			Duration: &metav1.Duration{Duration: viper.GetDuration("duration")},
+			IsCA:     viper.GetBool("is-ca"),
+			Usages:   usages,
+		},
+	}
+
+	cas := &controllers.GoogleCAS{
+		Endpoint:    viper.GetString("cas-endpoint"),
+		ClusterName: viper.GetString("cluster-name"),
+	}
+	bundle, err := cas.Sign(ctx, signer.CertificateRequestObjectFromCertificateRequest(cr), issuerObj)
+	if err != nil {
+		return fmt.Errorf("failed to sign the request: %w", err)
+	}
+
+	if name := viper.GetString("secret-name"); name != "" {
+		return writeSecret(stdout, name, bundle)
+	}
+	return writeFiles(viper.GetString("output-dir"), bundle)
+}
+
+// signIssuer returns the issuer the sign command signs with: the one in the
+// --issuer manifest, or a cluster issuer, with the spec fields that are set
+// by flags overridden. The spec is defaulted and validated like the webhook
+// does.
+func signIssuer() (issuerapi.Issuer, error) {
+	issuerObj := issuerapi.Issuer(&issuersv1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: signRequestName}})
+	if path := viper.GetString("issuer"); path != "" {
+		var err error
+		if issuerObj, err = readIssuer(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var spec *issuersv1beta1.GoogleCASIssuerSpec
+	switch t := issuerObj.(type) {
+	case *issuersv1beta1.GoogleCASIssuer:
+		spec = &t.Spec
+	case *issuersv1beta1.GoogleCASClusterIssuer:
+		spec = &t.Spec
+	}
+	for flag, value := range map[string]*string{
+		"project":                  &spec.Project,
+		"location":                 &spec.Location,
+		"ca-pool-id":               &spec.CaPoolId,
+		"certificate-authority-id": &spec.CertificateAuthorityId,
+		"certificate-template":     &spec.CertificateTemplate,
+	} {
+		if viper.IsSet(flag) {
+			*value = viper.GetString(flag)
+		}
+	}
+	if viper.IsSet("subject-mode") {
+		spec.SubjectMode = issuersv1beta1.SubjectMode(viper.GetString("subject-mode"))
+	}
+	if viper.IsSet("ca-fetch-mode") {
+		spec.CAFetchMode = issuersv1beta1.CAFetchMode(viper.GetString("ca-fetch-mode"))
+	}
+	if viper.IsSet("certificate-labels") {
+		spec.CertificateLabels = viper.GetStringMapString("certificate-labels")
+	}
+
+	webhooks.SetDefaultsGoogleCASIssuerSpec(spec)
+	if err := webhooks.ValidateGoogleCASIssuerSpec(spec, field.NewPath("spec")).ToAggregate(); err != nil {
+		return nil, fmt.Errorf("invalid issuer: %w", err)
+	}
+	return issuerObj, nil
+}
+
+// readIssuer reads a GoogleCASIssuer or GoogleCASClusterIssuer manifest.
+func readIssuer(path string) (issuerapi.Issuer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to parse issuer %s: %w", path, err)
+	}
+	if typeMeta.APIVersion != issuersv1beta1.GroupVersion.String() {
+		return nil, fmt.Errorf("issuer %s has apiVersion %q, expected %q", path, typeMeta.APIVersion, issuersv1beta1.GroupVersion)
+	}
+	var issuerObj issuerapi.Issuer
+	switch typeMeta.Kind {
+	case "GoogleCASIssuer":
+		issuerObj = &issuersv1beta1.GoogleCASIssuer{}
+	case "GoogleCASClusterIssuer":
+		issuerObj = &issuersv1beta1.GoogleCASClusterIssuer{}
+	default:
+		return nil, fmt.Errorf("issuer %s has kind %q, expected GoogleCASIssuer or GoogleCASClusterIssuer", path, typeMeta.Kind)
+	}
+	if err := yaml.UnmarshalStrict(data, issuerObj); err != nil {
+		return nil, fmt.Errorf("failed to parse issuer %s: %w", path, err)
+	}
+	return issuerObj, nil
+}
+
+// writeFiles writes the certificate chain and CA of bundle to tls.crt and
+// ca.crt in dir.
+func writeFiles(dir string, bundle signer.PEMBundle) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, corev1.TLSCertKey), bundle.ChainPEM, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, cmmetav1.TLSCAKey), bundle.CAPEM, 0o644)
+}
+
+// writeSecret prints a Secret manifest with the certificate chain and CA of
+// bundle, in the layout of the Secrets of cert-manager Certificates. The
+// Secret is a kubernetes.io/tls Secret if --key is set.
+func writeSecret(w io.Writer, name string, bundle signer.PEMBundle) error {
+	secret := corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: viper.GetString("secret-namespace")},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			corev1.TLSCertKey: bundle.ChainPEM,
+			cmmetav1.TLSCAKey: bundle.CAPEM,
+		},
+	}
+	if path := viper.GetString("key"); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		secret.Type = corev1.SecretTypeTLS
+		secret.Data[corev1.TLSPrivateKeyKey] = key
+	}
+
+	manifest, err := yaml.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(manifest)
+	return err
+}
@@ -0,0 +1,645 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package casfake implements an in-process fake of the Google Certificate
+// Authority Service API, for testing the issuer without access to Google
+// Cloud. It holds CA pools, certificate authorities with keys generated in
+// memory, certificate templates and issued certificates, and signs CSRs for
+// real.
+package casfake
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultLifetime is the lifetime of certificates requested without one.
+const DefaultLifetime = 30 * 24 * time.Hour
+
+var (
+	labelKeyRegexp   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	labelValueRegexp = regexp.MustCompile(`^[a-z0-9_-]{0,63}$`)
+)
+
+// idempotentRequest is the certificate created for a request ID, and a hash
+// of the request that created it.
+type idempotentRequest struct {
+	name string
+	hash [sha256.Size]byte
+}
+
+type certificateAuthority struct {
+	pb   *casapi.CertificateAuthority
+	key  crypto.Signer
+	cert *x509.Certificate
+}
+
+// Server is a fake Certificate Authority Service. The zero value is not
+// usable, use New.
+type Server struct {
+	casapi.UnimplementedCertificateAuthorityServiceServer
+
+	// Now returns the current time, and defaults to time.Now.
+	Now func() time.Time
+
+	mu           sync.Mutex
+	pools        map[string]*casapi.CaPool
+	cas          map[string]*certificateAuthority
+	templates    map[string]*casapi.CertificateTemplate
+	certificates map[string]*casapi.Certificate
+	requestIDs   map[string]idempotentRequest
+	permissions  map[string][]string
+	errs         map[string]error
+	poolErrs     map[string]error
+	calls        map[string]int
+
+	grpcServer *grpc.Server
+}
+
+// New returns an empty fake.
+func New() *Server {
+	return &Server{
+		Now:          time.Now,
+		pools:        make(map[string]*casapi.CaPool),
+		cas:          make(map[string]*certificateAuthority),
+		templates:    make(map[string]*casapi.CertificateTemplate),
+		certificates: make(map[string]*casapi.Certificate),
+		requestIDs:   make(map[string]idempotentRequest),
+		permissions:  make(map[string][]string),
+		errs:         make(map[string]error),
+		poolErrs:     make(map[string]error),
+		calls:        make(map[string]int),
+	}
+}
+
+// Start serves the fake on a random local port without TLS, and returns its
+// address.
+func (s *Server) Start() (string, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.grpcServer = grpc.NewServer()
+	casapi.RegisterCertificateAuthorityServiceServer(s.grpcServer, s)
+	iampb.RegisterIAMPolicyServer(s.grpcServer, &iamServer{s: s})
+	go func() { _ = s.grpcServer.Serve(lis) }()
+	return lis.Addr().String(), nil
+}
+
+// Stop stops serving the fake.
+func (s *Server) Stop() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+}
+
+// AddCaPool adds an ENTERPRISE tier CA pool and returns its resource name.
+func (s *Server) AddCaPool(project, location, id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := fmt.Sprintf("projects/%s/locations/%s/caPools/%s", project, location, id)
+	s.pools[name] = &casapi.CaPool{Name: name, Tier: casapi.CaPool_ENTERPRISE}
+	return name
+}
+
+// AddCertificateAuthority adds a self-signed certificate authority to a pool,
+// with a key generated in memory, and returns its certificate.
+func (s *Server) AddCertificateAuthority(pool, id string, state casapi.CertificateAuthority_State) (*x509.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: id, Organization: []string{"casfake"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pools[pool]; !ok {
+		return nil, fmt.Errorf("unknown CA pool %s", pool)
+	}
+	name := pool + "/certificateAuthorities/" + id
+	s.cas[name] = &certificateAuthority{
+		pb: &casapi.CertificateAuthority{
+			Name:              name,
+			Type:              casapi.CertificateAuthority_SELF_SIGNED,
+			Tier:              casapi.CaPool_ENTERPRISE,
+			State:             state,
+			PemCaCertificates: []string{encodeCertificate(cert)},
+		},
+		key:  key,
+		cert: cert,
+	}
+	return cert, nil
+}
+
+// SetCertificateAuthorityState changes the state of a certificate authority.
+func (s *Server) SetCertificateAuthorityState(name string, state casapi.CertificateAuthority_State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ca, ok := s.cas[name]; ok {
+		ca.pb.State = state
+	}
+}
+
+// AddCertificateTemplate adds a certificate template and returns its resource
+// name.
+func (s *Server) AddCertificateTemplate(project, location, id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := fmt.Sprintf("projects/%s/locations/%s/certificateTemplates/%s", project, location, id)
+	s.templates[name] = &casapi.CertificateTemplate{Name: name}
+	return name
+}
+
+// SetPermissions sets the permissions TestIamPermissions reports as granted
+// on a resource. All permissions are granted on resources without any set.
+func (s *Server) SetPermissions(resource string, permissions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.permissions[resource] = permissions
+}
+
+// SetError makes every call to an RPC method, e.g. "CreateCertificate",
+// fail with err until it is set to nil.
+func (s *Server) SetError(method string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.errs, method)
+		return
+	}
+	s.errs[method] = err
+}
+
+// SetPoolError makes every call on a CA pool or the resources in it fail with
+// err until it is set to nil, e.g. to simulate a regional outage.
+func (s *Server) SetPoolError(pool string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.poolErrs, pool)
+		return
+	}
+	s.poolErrs[pool] = err
+}
+
+// Calls returns how often an RPC method has been called.
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[method]
+}
+
+// Certificates returns copies of all issued certificates.
+func (s *Server) Certificates() []*casapi.Certificate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var certificates []*casapi.Certificate
+	for _, name := range sortedKeys(s.certificates) {
+		certificates = append(certificates, proto.Clone(s.certificates[name]).(*casapi.Certificate))
+	}
+	return certificates
+}
+
+// call records a call to method on resource and returns the error set for
+// the method or the CA pool of the resource, if any. It must be called with
+// s.mu held.
+func (s *Server) call(method, resource string) error {
+	s.calls[method]++
+	if err := s.errs[method]; err != nil {
+		return err
+	}
+	for pool, err := range s.poolErrs {
+		if resource == pool || strings.HasPrefix(resource, pool+"/") {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetCaPool(_ context.Context, req *casapi.GetCaPoolRequest) (*casapi.CaPool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("GetCaPool", req.GetName()); err != nil {
+		return nil, err
+	}
+	pool, ok := s.pools[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetName())
+	}
+	return proto.Clone(pool).(*casapi.CaPool), nil
+}
+
+func (s *Server) ListCertificateAuthorities(_ context.Context, req *casapi.ListCertificateAuthoritiesRequest) (*casapi.ListCertificateAuthoritiesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("ListCertificateAuthorities", req.GetParent()); err != nil {
+		return nil, err
+	}
+	if _, ok := s.pools[req.GetParent()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetParent())
+	}
+	resp := &casapi.ListCertificateAuthoritiesResponse{}
+	for _, ca := range s.poolCAs(req.GetParent()) {
+		resp.CertificateAuthorities = append(resp.CertificateAuthorities, proto.Clone(ca.pb).(*casapi.CertificateAuthority))
+	}
+	return resp, nil
+}
+
+func (s *Server) FetchCaCerts(_ context.Context, req *casapi.FetchCaCertsRequest) (*casapi.FetchCaCertsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("FetchCaCerts", req.GetCaPool()); err != nil {
+		return nil, err
+	}
+	if _, ok := s.pools[req.GetCaPool()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetCaPool())
+	}
+	resp := &casapi.FetchCaCertsResponse{}
+	for _, ca := range s.poolCAs(req.GetCaPool()) {
+		switch ca.pb.GetState() {
+		case casapi.CertificateAuthority_ENABLED, casapi.CertificateAuthority_DISABLED, casapi.CertificateAuthority_STAGED:
+			resp.CaCerts = append(resp.CaCerts, &casapi.FetchCaCertsResponse_CertChain{
+				Certificates: slices.Clone(ca.pb.GetPemCaCertificates()),
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) CreateCertificate(_ context.Context, req *casapi.CreateCertificateRequest) (*casapi.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("CreateCertificate", req.GetParent()); err != nil {
+		return nil, err
+	}
+	if _, ok := s.pools[req.GetParent()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetParent())
+	}
+
+	hash, err := requestHash(req)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if req.GetRequestId() != "" {
+		if previous, ok := s.requestIDs[req.GetRequestId()]; ok {
+			if previous.hash != hash {
+				return nil, status.Errorf(codes.InvalidArgument, "request ID %s was used by a different request", req.GetRequestId())
+			}
+			return proto.Clone(s.certificates[previous.name]).(*casapi.Certificate), nil
+		}
+	}
+
+	certificateID := req.GetCertificateId()
+	if certificateID == "" {
+		serial, err := randomSerial()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		certificateID = serial.Text(16)
+	}
+	name := req.GetParent() + "/certificates/" + certificateID
+	if _, ok := s.certificates[name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "Resource '%s' already exists", name)
+	}
+
+	if template := req.GetCertificate().GetCertificateTemplate(); template != "" {
+		if _, ok := s.templates[template]; !ok {
+			return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", template)
+		}
+	}
+
+	for key, value := range req.GetCertificate().GetLabels() {
+		if !labelKeyRegexp.MatchString(key) || !labelValueRegexp.MatchString(value) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid label %s=%s", key, value)
+		}
+	}
+
+	ca, err := s.issuingCA(req.GetParent(), req.GetIssuingCertificateAuthorityId())
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := parseCSR(req.GetCertificate().GetPemCsr())
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := DefaultLifetime
+	if req.GetCertificate().GetLifetime() != nil {
+		lifetime = req.GetCertificate().GetLifetime().AsDuration()
+	}
+	if lifetime <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "certificate.lifetime must be positive")
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	now := s.Now()
+	notAfter := now.Add(lifetime)
+	if notAfter.After(ca.cert.NotAfter) {
+		notAfter = ca.cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        csr.Subject,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+		URIs:           csr.URIs,
+		EmailAddresses: csr.EmailAddresses,
+		NotBefore:      now,
+		NotAfter:       notAfter,
+		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if req.GetCertificate().GetSubjectMode() == casapi.SubjectRequestMode_REFLECTED_SPIFFE {
+		// CAS derives the SPIFFE ID from the caller's identity, which the
+		// fake doesn't know, so it reflects the one from the CSR instead.
+		if len(csr.URIs) != 1 || csr.URIs[0].Scheme != "spiffe" {
+			return nil, status.Error(codes.InvalidArgument, "REFLECTED_SPIFFE requires exactly one SPIFFE ID in the CSR")
+		}
+		template.Subject = pkix.Name{}
+		template.DNSNames, template.IPAddresses, template.EmailAddresses = nil, nil, nil
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	issued := proto.Clone(req.GetCertificate()).(*casapi.Certificate)
+	issued.Name = name
+	issued.IssuerCertificateAuthority = ca.pb.GetName()
+	issued.Lifetime = durationpb.New(cert.NotAfter.Sub(cert.NotBefore))
+	issued.PemCertificate = encodeCertificate(cert)
+	issued.PemCertificateChain = slices.Clone(ca.pb.GetPemCaCertificates())
+	issued.CreateTime = timestamppb.New(now)
+	issued.UpdateTime = timestamppb.New(now)
+	issued.CertificateDescription = &casapi.CertificateDescription{
+		SubjectDescription: &casapi.CertificateDescription_SubjectDescription{
+			Subject:         &casapi.Subject{CommonName: cert.Subject.CommonName},
+			SubjectAltName:  &casapi.SubjectAltNames{DnsNames: cert.DNSNames},
+			HexSerialNumber: cert.SerialNumber.Text(16),
+			Lifetime:        issued.Lifetime,
+			NotBeforeTime:   timestamppb.New(cert.NotBefore),
+			NotAfterTime:    timestamppb.New(cert.NotAfter),
+		},
+	}
+
+	if req.GetValidateOnly() {
+		return issued, nil
+	}
+	s.certificates[name] = issued
+	if req.GetRequestId() != "" {
+		s.requestIDs[req.GetRequestId()] = idempotentRequest{name: name, hash: hash}
+	}
+	return proto.Clone(issued).(*casapi.Certificate), nil
+}
+
+// requestHash hashes a CreateCertificateRequest without its request ID, so
+// that retries can be told apart from different requests reusing the ID.
+func requestHash(req *casapi.CreateCertificateRequest) ([sha256.Size]byte, error) {
+	req = proto.Clone(req).(*casapi.CreateCertificateRequest)
+	req.RequestId = ""
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+func (s *Server) GetCertificateTemplate(_ context.Context, req *casapi.GetCertificateTemplateRequest) (*casapi.CertificateTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("GetCertificateTemplate", req.GetName()); err != nil {
+		return nil, err
+	}
+	template, ok := s.templates[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetName())
+	}
+	return proto.Clone(template).(*casapi.CertificateTemplate), nil
+}
+
+func (s *Server) GetCertificate(_ context.Context, req *casapi.GetCertificateRequest) (*casapi.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("GetCertificate", req.GetName()); err != nil {
+		return nil, err
+	}
+	cert, ok := s.certificates[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetName())
+	}
+	return proto.Clone(cert).(*casapi.Certificate), nil
+}
+
+func (s *Server) ListCertificates(_ context.Context, req *casapi.ListCertificatesRequest) (*casapi.ListCertificatesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("ListCertificates", req.GetParent()); err != nil {
+		return nil, err
+	}
+	resp := &casapi.ListCertificatesResponse{}
+	for _, name := range sortedKeys(s.certificates) {
+		if strings.HasPrefix(name, req.GetParent()+"/certificates/") {
+			resp.Certificates = append(resp.Certificates, proto.Clone(s.certificates[name]).(*casapi.Certificate))
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) RevokeCertificate(_ context.Context, req *casapi.RevokeCertificateRequest) (*casapi.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("RevokeCertificate", req.GetName()); err != nil {
+		return nil, err
+	}
+	cert, ok := s.certificates[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetName())
+	}
+	if cert.GetRevocationDetails() != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Certificate '%s' is already revoked", req.GetName())
+	}
+	cert.RevocationDetails = &casapi.Certificate_RevocationDetails{
+		RevocationState: req.GetReason(),
+		RevocationTime:  timestamppb.New(s.Now()),
+	}
+	cert.UpdateTime = timestamppb.New(s.Now())
+	return proto.Clone(cert).(*casapi.Certificate), nil
+}
+
+// poolCAs returns the certificate authorities in a pool, sorted by name. It
+// must be called with s.mu held.
+func (s *Server) poolCAs(pool string) []*certificateAuthority {
+	var cas []*certificateAuthority
+	for _, name := range sortedKeys(s.cas) {
+		if strings.HasPrefix(name, pool+"/certificateAuthorities/") {
+			cas = append(cas, s.cas[name])
+		}
+	}
+	return cas
+}
+
+// issuingCA returns the CA that issues a certificate in pool: the requested
+// one if id is set, and the first enabled one otherwise. It must be called
+// with s.mu held.
+func (s *Server) issuingCA(pool, id string) (*certificateAuthority, error) {
+	if id != "" {
+		ca, ok := s.cas[pool+"/certificateAuthorities/"+id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "Resource '%s/certificateAuthorities/%s' was not found", pool, id)
+		}
+		if ca.pb.GetState() != casapi.CertificateAuthority_ENABLED {
+			return nil, status.Errorf(codes.FailedPrecondition, "Certificate authority '%s' is not enabled", ca.pb.GetName())
+		}
+		return ca, nil
+	}
+	for _, ca := range s.poolCAs(pool) {
+		if ca.pb.GetState() == casapi.CertificateAuthority_ENABLED {
+			return ca, nil
+		}
+	}
+	return nil, status.Errorf(codes.FailedPrecondition, "CA pool '%s' has no enabled certificate authorities", pool)
+}
+
+// iamServer implements TestIamPermissions for the fake, which the CAS client
+// calls through the IAMPolicy service.
+type iamServer struct {
+	iampb.UnimplementedIAMPolicyServer
+	s *Server
+}
+
+func (i *iamServer) TestIamPermissions(_ context.Context, req *iampb.TestIamPermissionsRequest) (*iampb.TestIamPermissionsResponse, error) {
+	s := i.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.call("TestIamPermissions", req.GetResource()); err != nil {
+		return nil, err
+	}
+	if _, ok := s.pools[req.GetResource()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "Resource '%s' was not found", req.GetResource())
+	}
+	granted, restricted := s.permissions[req.GetResource()]
+	resp := &iampb.TestIamPermissionsResponse{}
+	for _, permission := range req.GetPermissions() {
+		if !restricted || slices.Contains(granted, permission) {
+			resp.Permissions = append(resp.Permissions, permission)
+		}
+	}
+	return resp, nil
+}
+
+func parseCSR(pemCSR string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(pemCSR))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, status.Error(codes.InvalidArgument, "certificate.pem_csr is not a PEM encoded certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "certificate.pem_csr is invalid: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "certificate.pem_csr has an invalid signature: %v", err)
+	}
+	return csr, nil
+}
+
+func encodeCertificate(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
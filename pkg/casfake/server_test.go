@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package casfake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func testCSR(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "example.com"},
+		DNSNames: []string{"example.com"},
+	}, key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+func TestServer(t *testing.T) {
+	fake := New()
+	pool := fake.AddCaPool("p", "l", "pool")
+	root, err := fake.AddCertificateAuthority(pool, "root", casapi.CertificateAuthority_ENABLED)
+	require.NoError(t, err)
+
+	addr, err := fake.Start()
+	require.NoError(t, err)
+	t.Cleanup(fake.Stop)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := casapi.NewCertificateAuthorityServiceClient(conn)
+
+	req := &casapi.CreateCertificateRequest{
+		Parent:        pool,
+		CertificateId: "cert",
+		RequestId:     "request",
+		Certificate: &casapi.Certificate{
+			CertificateConfig: &casapi.Certificate_PemCsr{PemCsr: testCSR(t)},
+			Lifetime:          durationpb.New(time.Hour),
+		},
+	}
+	cert, err := client.CreateCertificate(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, pool+"/certificates/cert", cert.GetName())
+	assert.Equal(t, pool+"/certificateAuthorities/root", cert.GetIssuerCertificateAuthority())
+
+	block, _ := pem.Decode([]byte(cert.GetPemCertificate()))
+	require.NotNil(t, block)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.NoError(t, leaf.CheckSignatureFrom(root))
+	assert.Equal(t, time.Hour, leaf.NotAfter.Sub(leaf.NotBefore))
+
+	// A retry with the same request ID returns the same certificate, a
+	// different request reusing the ID is rejected, and a new request for the
+	// same certificate ID conflicts.
+	retried, err := client.CreateCertificate(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, cert.GetPemCertificate(), retried.GetPemCertificate())
+	_, err = client.CreateCertificate(t.Context(), &casapi.CreateCertificateRequest{
+		Parent:        pool,
+		CertificateId: "other-cert",
+		RequestId:     req.GetRequestId(),
+		Certificate:   req.GetCertificate(),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CreateCertificate(t.Context(), &casapi.CreateCertificateRequest{
+		Parent:        pool,
+		CertificateId: req.GetCertificateId(),
+		RequestId:     req.GetRequestId(),
+		Certificate: &casapi.Certificate{
+			CertificateConfig: &casapi.Certificate_PemCsr{PemCsr: testCSR(t)},
+			Lifetime:          req.GetCertificate().GetLifetime(),
+		},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	req.RequestId = "other-request"
+	_, err = client.CreateCertificate(t.Context(), req)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// Validate-only requests are signed, but not stored.
+	_, err = client.CreateCertificate(t.Context(), &casapi.CreateCertificateRequest{
+		Parent:        pool,
+		CertificateId: "validate-only",
+		Certificate:   req.GetCertificate(),
+		ValidateOnly:  true,
+	})
+	require.NoError(t, err)
+
+	list, err := client.ListCertificates(t.Context(), &casapi.ListCertificatesRequest{Parent: pool})
+	require.NoError(t, err)
+	assert.Len(t, list.GetCertificates(), 1)
+
+	revoked, err := client.RevokeCertificate(t.Context(), &casapi.RevokeCertificateRequest{
+		Name:   cert.GetName(),
+		Reason: casapi.RevocationReason_KEY_COMPROMISE,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, casapi.RevocationReason_KEY_COMPROMISE, revoked.GetRevocationDetails().GetRevocationState())
+	_, err = client.RevokeCertificate(t.Context(), &casapi.RevokeCertificateRequest{Name: cert.GetName()})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	fake.SetCertificateAuthorityState(pool+"/certificateAuthorities/root", casapi.CertificateAuthority_DISABLED)
+	req.CertificateId, req.RequestId = "cert-2", "request-2"
+	_, err = client.CreateCertificate(t.Context(), req)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "disabled CAs must not issue certificates")
+}
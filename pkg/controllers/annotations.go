@@ -0,0 +1,155 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+const (
+	// CertificateNameAnnotation records the resource name of the CAS
+	// certificate issued for a CertificateRequest.
+	CertificateNameAnnotation = "cas-issuer.jetstack.io/certificate-name"
+
+	// SerialNumberAnnotation records the hex encoded serial number of the CAS
+	// certificate issued for a CertificateRequest.
+	SerialNumberAnnotation = "cas-issuer.jetstack.io/serial-number"
+
+	// IssuingCertificateAuthorityAnnotation records the resource name of the
+	// CA that issued the CAS certificate for a CertificateRequest.
+	IssuingCertificateAuthorityAnnotation = "cas-issuer.jetstack.io/issuing-certificate-authority"
+
+	// CAPoolAnnotation records the resource name of the CA pool the CAS
+	// certificate for a CertificateRequest was issued from.
+	CAPoolAnnotation = "cas-issuer.jetstack.io/ca-pool"
+
+	// CertificateTemplateAnnotation selects the certificate template a
+	// CertificateRequest is signed with, out of the allowed certificate
+	// templates of its issuer.
+	CertificateTemplateAnnotation = "cas-issuer.jetstack.io/certificate-template"
+
+	// RevokedAnnotation records when the CAS certificate issued for a
+	// CertificateRequest was revoked.
+	RevokedAnnotation = "cas-issuer.jetstack.io/revoked-at"
+)
+
+// certificateAnnotations returns the annotations that link a
+// CertificateRequest to the CAS certificate issued for it from pool.
+func certificateAnnotations(pool string, certificate *casapi.Certificate) map[string]string {
+	annotations := map[string]string{
+		CertificateNameAnnotation:             certificate.GetName(),
+		IssuingCertificateAuthorityAnnotation: certificate.GetIssuerCertificateAuthority(),
+		CAPoolAnnotation:                      pool,
+	}
+	if serial := certificateSerialNumber(certificate); serial != "" {
+		annotations[SerialNumberAnnotation] = serial
+	}
+	return annotations
+}
+
+// certificateSerialNumber returns the hex encoded serial number of
+// certificate, as CAS and gcloud display it. It is read from the leaf
+// certificate if CAS didn't describe it.
+func certificateSerialNumber(certificate *casapi.Certificate) string {
+	if serial := certificate.GetCertificateDescription().GetSubjectDescription().GetHexSerialNumber(); serial != "" {
+		return strings.ToLower(serial)
+	}
+	block, _ := pem.Decode([]byte(certificate.GetPemCertificate()))
+	if block == nil {
+		return ""
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return leaf.SerialNumber.Text(16)
+}
+
+// recordCertificate annotates a CertificateRequest with the CAS certificate
+// issued for it from pool, emits an Event naming it, and adds the revocation
+// finalizer if its issuer revokes certificates. Kubernetes
+// CertificateSigningRequests, and requests signed without a cluster, are left
+// alone.
+//
+// Failing to record the certificate only fails signing if the certificate
+// may need to be revoked later; the request is then retried, and the retry
+// finds the certificate that was already issued.
+func (o *GoogleCAS) recordCertificate(ctx context.Context, cr signer.CertificateRequestObject, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec, pool string, certificate *casapi.Certificate) error {
+	if cr.GetNamespace() == "" || o.client == nil {
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "recordCertificate")
+	defer span.End()
+	revocation := issuerSpec.Revocation != nil
+	annotations := certificateAnnotations(pool, certificate)
+
+	err := func() error {
+		var current cmapi.CertificateRequest
+		if err := o.client.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, &current); err != nil {
+			return err
+		}
+		patch := client.MergeFromWithOptions(current.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		recorded := true
+		for key, value := range annotations {
+			if current.Annotations[key] != value {
+				metav1.SetMetaDataAnnotation(&current.ObjectMeta, key, value)
+				recorded = false
+			}
+		}
+		if revocation && current.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(&current, revocationFinalizer) {
+			controllerutil.AddFinalizer(&current, revocationFinalizer)
+			recorded = false
+		}
+		if recorded {
+			// A retry of a request that was already recorded.
+			return nil
+		}
+		if err := o.client.Patch(ctx, &current, patch); err != nil {
+			return err
+		}
+		if o.recorder != nil {
+			o.recorder.Eventf(&current, nil, corev1.EventTypeNormal, "CASCertificateIssued", "Sign",
+				"Issued CAS certificate %s with serial number %s by %s%s",
+				certificate.GetName(), annotations[SerialNumberAnnotation], certificate.GetIssuerCertificateAuthority(), traceSuffix(ctx))
+		}
+		return nil
+	}()
+	if err == nil {
+		return nil
+	}
+	if revocation {
+		return fmt.Errorf("failed to record CAS certificate %s on the request: %w", certificate.GetName(), err)
+	}
+	ctrl.LoggerFrom(ctx).V(1).Info("Failed to record CAS certificate on the request", logKeyCASCertificate, certificate.GetName(), "error", err.Error())
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestCertificateSerialNumber(t *testing.T) {
+	assert.Equal(t, "1a2b", certificateSerialNumber(&casapi.Certificate{
+		CertificateDescription: &casapi.CertificateDescription{
+			SubjectDescription: &casapi.CertificateDescription_SubjectDescription{HexSerialNumber: "1A2B"},
+		},
+	}))
+	assert.Empty(t, certificateSerialNumber(&casapi.Certificate{}))
+}
+
+func TestRecordCertificate(t *testing.T) {
+	casServer, cas, spec := newFakeCAS(t)
+	recorder := &events.FakeRecorder{Events: make(chan string, 10)}
+	cas.recorder = recorder
+
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"}, Spec: *spec}
+	cr := testCertificateRequestFor(t, "default", "cert-1", cmmetav1.IssuerReference{
+		Group: issuersv1beta1.GroupVersion.Group,
+		Kind:  "GoogleCASIssuer",
+		Name:  "issuer",
+	})
+	cr.UID = "cr-uid"
+	require.NoError(t, cas.client.Create(t.Context(), cr))
+
+	// Signing twice, like a retried request, records the certificate once.
+	for range 2 {
+		_, err := cas.Sign(t.Context(), signer.CertificateRequestObjectFromCertificateRequest(cr), issuer)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, casServer.Certificates(), 1)
+	certificate := casServer.Certificates()[0]
+	require.NoError(t, cas.client.Get(t.Context(), client.ObjectKeyFromObject(cr), cr))
+	assert.Equal(t, certificate.GetName(), cr.Annotations[CertificateNameAnnotation])
+	assert.Equal(t, certificate.GetCertificateDescription().GetSubjectDescription().GetHexSerialNumber(), cr.Annotations[SerialNumberAnnotation])
+	assert.Equal(t, "projects/my-project/locations/us-east1/caPools/my-pool/certificateAuthorities/root-1", cr.Annotations[IssuingCertificateAuthorityAnnotation])
+	assert.Equal(t, "projects/my-project/locations/us-east1/caPools/my-pool", cr.Annotations[CAPoolAnnotation])
+	assert.Empty(t, cr.Finalizers, "issuers without a revocation policy don't add the finalizer")
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "CASCertificateIssued Issued CAS certificate "+certificate.GetName())
+}
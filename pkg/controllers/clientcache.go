@@ -0,0 +1,204 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	privateca "cloud.google.com/go/security/privateca/apiv1"
+)
+
+// defaultClientIdleTimeout is used when GoogleCAS.ClientIdleTimeout is unset.
+const defaultClientIdleTimeout = 10 * time.Minute
+
+// casClientKey identifies the credential source a CAS client was built from.
+// The zero value identifies the controller's ambient credentials. Clients
+// built from a Secret include its resourceVersion, so a rotated Secret never
+// reuses a client that was built from the previous credentials.
+type casClientKey struct {
+	secretNamespace       string
+	secretName            string
+	secretKey             string
+	secretResourceVersion string
+
+	// workloadIdentity identifies the ServiceAccount and pool provider used
+	// instead of a Secret, see workloadIdentityKey.
+	workloadIdentity string
+
+	// impersonation identifies the service account impersonated on top of
+	// the credentials, see impersonationKey.
+	impersonation string
+}
+
+// supersedes reports whether k refers to a newer version of the same Secret
+// key that old was built from.
+func (k casClientKey) supersedes(old casClientKey) bool {
+	return k.secretName != "" &&
+		k.secretNamespace == old.secretNamespace &&
+		k.secretName == old.secretName &&
+		k.secretKey == old.secretKey &&
+		k.secretResourceVersion != old.secretResourceVersion
+}
+
+type casClientEntry struct {
+	client   *privateca.CertificateAuthorityClient
+	refs     int
+	lastUsed time.Time
+
+	// retired entries are no longer handed out and are closed as soon as
+	// the last caller releases them.
+	retired bool
+}
+
+// casClientCache keeps CAS API clients alive between Sign and Check calls, so
+// that issuing a certificate doesn't pay for a fresh gRPC connection and token
+// exchange every time. Clients that haven't been used for idleTimeout are
+// closed, and all clients are closed when the manager stops.
+type casClientCache struct {
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[casClientKey]*casClientEntry
+	stopped bool
+}
+
+func newCASClientCache(idleTimeout time.Duration) *casClientCache {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultClientIdleTimeout
+	}
+	return &casClientCache{
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		entries:     make(map[casClientKey]*casClientEntry),
+	}
+}
+
+// get returns the cached client for key, building one with newClient if there
+// is none. The returned release func must be called once the caller is done
+// with the client.
+func (c *casClientCache) get(ctx context.Context, key casClientKey, newClient func(context.Context) (*privateca.CertificateAuthorityClient, error)) (*privateca.CertificateAuthorityClient, func(), error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		e.refs++
+		c.mu.Unlock()
+		return e.client, c.releaseFunc(e), nil
+	}
+	c.mu.Unlock()
+
+	// The client outlives this call, so it must not be tied to the
+	// cancellation of the reconcile that happened to build it.
+	casClient, err := newClient(context.WithoutCancel(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		// The manager is shutting down, hand out a client that is closed
+		// as soon as it is released.
+		e := &casClientEntry{client: casClient, refs: 1, retired: true}
+		return casClient, c.releaseFunc(e), nil
+	}
+
+	if e, ok := c.entries[key]; ok {
+		// Another caller built a client for the same key in the meantime.
+		casClient.Close()
+		e.refs++
+		return e.client, c.releaseFunc(e), nil
+	}
+
+	for k, e := range c.entries {
+		if key.supersedes(k) {
+			c.retireLocked(k, e)
+		}
+	}
+
+	e := &casClientEntry{client: casClient, refs: 1, lastUsed: c.now()}
+	c.entries[key] = e
+	return casClient, c.releaseFunc(e), nil
+}
+
+func (c *casClientCache) releaseFunc(e *casClientEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			e.refs--
+			e.lastUsed = c.now()
+			if e.retired && e.refs == 0 {
+				e.client.Close()
+			}
+		})
+	}
+}
+
+func (c *casClientCache) retireLocked(key casClientKey, e *casClientEntry) {
+	delete(c.entries, key)
+	e.retired = true
+	if e.refs == 0 {
+		e.client.Close()
+	}
+}
+
+// evictIdle closes all clients that haven't been used for idleTimeout.
+func (c *casClientCache) evictIdle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, e := range c.entries {
+		if e.refs == 0 && now.Sub(e.lastUsed) >= c.idleTimeout {
+			c.retireLocked(k, e)
+		}
+	}
+}
+
+// Start implements manager.Runnable. It periodically evicts idle clients and
+// closes all clients once ctx is done.
+func (c *casClientCache) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.stopped = true
+			for k, e := range c.entries {
+				c.retireLocked(k, e)
+			}
+			return nil
+		case <-ticker.C:
+			c.evictIdle()
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Clients are
+// built lazily, so the cache can run on every replica.
+func (c *casClientCache) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	privateca "cloud.google.com/go/security/privateca/apiv1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// countingClientFactory builds clients that never connect anywhere, and counts
+// how many have been built.
+func countingClientFactory(t *testing.T, built *int) func(context.Context) (*privateca.CertificateAuthorityClient, error) {
+	return func(ctx context.Context) (*privateca.CertificateAuthorityClient, error) {
+		*built++
+		return privateca.NewCertificateAuthorityClient(ctx,
+			option.WithEndpoint("127.0.0.1:1"),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+	}
+}
+
+func TestCASClientCacheReusesClients(t *testing.T) {
+	ctx := t.Context()
+	cache := newCASClientCache(time.Minute)
+
+	built := 0
+	newClient := countingClientFactory(t, &built)
+
+	key := casClientKey{secretNamespace: "ns", secretName: "sa", secretKey: "key.json", secretResourceVersion: "1"}
+	first, release1, err := cache.get(ctx, key, newClient)
+	require.NoError(t, err)
+	second, release2, err := cache.get(ctx, key, newClient)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, built)
+	assert.Equal(t, 2, cache.entries[key].refs)
+
+	release1()
+	release1() // releasing twice must not drop someone else's reference
+	assert.Equal(t, 1, cache.entries[key].refs)
+	release2()
+	assert.Equal(t, 0, cache.entries[key].refs)
+
+	_, release3, err := cache.get(ctx, casClientKey{}, newClient)
+	require.NoError(t, err)
+	release3()
+	assert.Equal(t, 2, built, "ambient credentials must not share a client with a Secret")
+}
+
+func TestCASClientCacheRetiresSupersededSecrets(t *testing.T) {
+	ctx := t.Context()
+	cache := newCASClientCache(time.Minute)
+
+	built := 0
+	newClient := countingClientFactory(t, &built)
+
+	oldKey := casClientKey{secretNamespace: "ns", secretName: "sa", secretKey: "key.json", secretResourceVersion: "1"}
+	otherKey := casClientKey{secretNamespace: "ns", secretName: "sa", secretKey: "other.json", secretResourceVersion: "1"}
+	newKey := casClientKey{secretNamespace: "ns", secretName: "sa", secretKey: "key.json", secretResourceVersion: "2"}
+
+	_, releaseOld, err := cache.get(ctx, oldKey, newClient)
+	require.NoError(t, err)
+	_, releaseOther, err := cache.get(ctx, otherKey, newClient)
+	require.NoError(t, err)
+	releaseOther()
+
+	oldEntry := cache.entries[oldKey]
+	_, releaseNew, err := cache.get(ctx, newKey, newClient)
+	require.NoError(t, err)
+	defer releaseNew()
+
+	assert.NotContains(t, cache.entries, oldKey)
+	assert.Contains(t, cache.entries, otherKey)
+	assert.Contains(t, cache.entries, newKey)
+	assert.True(t, oldEntry.retired)
+
+	// The old client is still in use and only closed on release.
+	assert.Equal(t, 1, oldEntry.refs)
+	releaseOld()
+	assert.Equal(t, 0, oldEntry.refs)
+}
+
+func TestCASClientCacheEvictsIdleClients(t *testing.T) {
+	ctx := t.Context()
+	cache := newCASClientCache(time.Minute)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	built := 0
+	newClient := countingClientFactory(t, &built)
+
+	idleKey := casClientKey{}
+	busyKey := casClientKey{secretNamespace: "ns", secretName: "sa", secretKey: "key.json", secretResourceVersion: "1"}
+
+	_, releaseIdle, err := cache.get(ctx, idleKey, newClient)
+	require.NoError(t, err)
+	releaseIdle()
+	_, releaseBusy, err := cache.get(ctx, busyKey, newClient)
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	cache.evictIdle()
+	assert.Len(t, cache.entries, 2)
+
+	now = now.Add(time.Minute)
+	cache.evictIdle()
+	assert.NotContains(t, cache.entries, idleKey)
+	assert.Contains(t, cache.entries, busyKey, "clients that are in use must not be evicted")
+
+	releaseBusy()
+}
+
+func TestCASClientCacheClosesClientsOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cache := newCASClientCache(time.Minute)
+
+	built := 0
+	newClient := countingClientFactory(t, &built)
+
+	_, release, err := cache.get(ctx, casClientKey{}, newClient)
+	require.NoError(t, err)
+	release()
+
+	done := make(chan error)
+	go func() { done <- cache.Start(ctx) }()
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Empty(t, cache.entries)
+
+	// Clients handed out after shutdown are not cached.
+	_, release, err = cache.get(t.Context(), casClientKey{}, newClient)
+	require.NoError(t, err)
+	release()
+	assert.Empty(t, cache.entries)
+	assert.Equal(t, 2, built)
+}
@@ -0,0 +1,397 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"cloud.google.com/go/iam/apiv1/iampb"
+	privateca "cloud.google.com/go/security/privateca/apiv1"
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// DoctorStatus is the outcome of a DoctorCheck.
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "Pass"
+	// DoctorWarn is a check that passed, but points at a problem to come,
+	// or that couldn't be completed with the issuer's permissions.
+	DoctorWarn DoctorStatus = "Warn"
+	DoctorFail DoctorStatus = "Fail"
+	// DoctorSkip is a check that doesn't apply to the issuer, or that an
+	// earlier failure makes pointless.
+	DoctorSkip DoctorStatus = "Skip"
+)
+
+// DoctorCheck is one step of Diagnose.
+type DoctorCheck struct {
+	Name string `json:"name"`
+	// Target is the CA pool or certificate template the check is about, if
+	// any.
+	Target  string       `json:"target,omitempty"`
+	Status  DoctorStatus `json:"status"`
+	Message string       `json:"message"`
+}
+
+// doctorExpiryWarning is how long before their expiry CAs are reported.
+const doctorExpiryWarning = 30 * 24 * time.Hour
+
+// Diagnose checks step by step what signing requests with an issuer needs:
+// its credentials, each of its CA pools and their CAs, its certificate
+// templates, the IAM permissions of its credentials, and a validate-only
+// issuance from each CA pool. Credentials are resolved like createCasClient
+// does; kubeClient reads credentials Secrets and ServiceAccount tokens, and
+// may be nil for issuers that use Application Default Credentials.
+func (o *GoogleCAS) Diagnose(ctx context.Context, kubeClient client.Client, issuerObj issuerapi.Issuer) []DoctorCheck {
+	cas := &GoogleCAS{
+		client:           kubeClient,
+		Endpoint:         o.Endpoint,
+		InsecureEndpoint: o.InsecureEndpoint,
+		ClusterName:      o.ClusterName,
+	}
+	issuerSpec, resourceNamespace := cas.extractIssuerSpec(issuerObj)
+
+	var checks []DoctorCheck
+	report := func(name, target string, status DoctorStatus, format string, args ...any) {
+		checks = append(checks, DoctorCheck{Name: name, Target: target, Status: status, Message: fmt.Sprintf(format, args...)})
+	}
+
+	casClient, release, err := cas.createCasClient(ctx, resourceNamespace, issuerSpec)
+	if err != nil {
+		report("Credentials", "", DoctorFail, "%v", err)
+		return checks
+	}
+	defer release()
+	report("Credentials", "", DoctorPass, "%s", cas.describeCredentials(ctx, resourceNamespace, issuerSpec))
+
+	targets := caPoolTargets(issuerSpec)
+	parents := make([]string, len(targets))
+	for i, target := range targets {
+		parents[i], err = buildParentString(target)
+		if err != nil {
+			report("CA pool", "", DoctorFail, "%v", err)
+			return checks
+		}
+	}
+
+	pools := make([]bool, len(targets))
+	for i, target := range targets {
+		pools[i] = diagnoseCAPool(ctx, casClient, parents[i], target, report)
+	}
+	diagnoseCertificateTemplates(ctx, casClient, issuerSpec, report)
+	for i, target := range targets {
+		diagnosePermissions(ctx, casClient, parents[i], target, report)
+		if !pools[i] {
+			report("Dry run", parents[i], DoctorSkip, "the CA pool failed its checks")
+			continue
+		}
+		cas.diagnoseIssuance(ctx, casClient, parents[i], target, report)
+	}
+	return checks
+}
+
+type doctorReport func(name, target string, status DoctorStatus, format string, args ...any)
+
+// describeCredentials returns the type of the credentials an issuer uses, and
+// the principal they authenticate as where it can be told.
+func (o *GoogleCAS) describeCredentials(ctx context.Context, resourceNamespace string, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) string {
+	var description string
+	switch {
+	case o.InsecureEndpoint:
+		return "none, the CAS endpoint is insecure"
+	case issuerSpec.WorkloadIdentityFederation != nil:
+		config := issuerSpec.WorkloadIdentityFederation
+		description = fmt.Sprintf("workload identity federation of ServiceAccount %s/%s with audience %s", resourceNamespace, config.ServiceAccountName, config.Audience)
+	case len(issuerSpec.Credentials.Name) > 0:
+		key := types.NamespacedName{Namespace: resourceNamespace, Name: issuerSpec.Credentials.Name}
+		description = fmt.Sprintf("key %s of Secret %s", issuerSpec.Credentials.Key, key)
+		var secret corev1.Secret
+		if err := o.client.Get(ctx, key, &secret); err == nil {
+			if principal := credentialsPrincipal(secret.Data[issuerSpec.Credentials.Key]); principal != "" {
+				description += ", " + principal
+			}
+		}
+	default:
+		description = "Application Default Credentials"
+		if credentials, err := google.FindDefaultCredentials(ctx, defaultImpersonationScopes...); err == nil {
+			principal := credentialsPrincipal(credentials.JSON)
+			if principal == "" && len(credentials.JSON) == 0 && metadata.OnGCEWithContext(ctx) {
+				if email, err := metadata.EmailWithContext(ctx, "default"); err == nil {
+					principal = "service account " + email
+				}
+			}
+			if principal != "" {
+				description += ", " + principal
+			}
+		}
+	}
+	if issuerSpec.Impersonate != nil {
+		description += ", impersonating " + issuerSpec.Impersonate.TargetPrincipal
+	}
+	return description
+}
+
+// credentialsPrincipal returns the principal of a Google credentials file, if
+// it names one.
+func credentialsPrincipal(credentialsJSON []byte) string {
+	var file struct {
+		Type                           string `json:"type"`
+		ClientEmail                    string `json:"client_email"`
+		Audience                       string `json:"audience"`
+		ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	}
+	if err := json.Unmarshal(credentialsJSON, &file); err != nil {
+		return ""
+	}
+	switch file.Type {
+	case "service_account":
+		return "service account " + file.ClientEmail
+	case "external_account":
+		// e.g. .../serviceAccounts/issuer@my-project.iam.gserviceaccount.com:generateAccessToken
+		if impersonationURL, err := url.Parse(file.ServiceAccountImpersonationURL); err == nil {
+			if email, ok := strings.CutSuffix(path.Base(impersonationURL.Path), ":generateAccessToken"); ok {
+				return "service account " + email + " through workload identity federation"
+			}
+		}
+		return "federated identity of " + file.Audience
+	case "authorized_user":
+		return "user account"
+	}
+	return ""
+}
+
+// diagnoseCAPool checks that a CA pool exists, and that it has an enabled CA
+// to issue certificates with. It reports whether the pool is usable, as far
+// as the issuer's permissions tell.
+func diagnoseCAPool(ctx context.Context, casClient *privateca.CertificateAuthorityClient, parent string, target *issuersv1beta1.GoogleCASIssuerSpec, report doctorReport) bool {
+	pool, err := casClient.GetCaPool(ctx, &casapi.GetCaPoolRequest{Name: parent})
+	switch {
+	case status.Code(err) == codes.PermissionDenied:
+		report("CA pool", parent, DoctorWarn, "not allowed to read the CA pool, which needs privateca.caPools.get")
+	case err != nil:
+		report("CA pool", parent, DoctorFail, "%v", casError("GetCaPool", err))
+		return false
+	default:
+		report("CA pool", parent, DoctorPass, "exists, %s tier", pool.GetTier())
+	}
+
+	var authorities []*casapi.CertificateAuthority
+	it := casClient.ListCertificateAuthorities(ctx, &casapi.ListCertificateAuthoritiesRequest{Parent: parent})
+	for {
+		ca, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			report("Certificate authorities", parent, DoctorWarn, "not allowed to list the CAs of the pool, which needs privateca.certificateAuthorities.list")
+			return true
+		}
+		if err != nil {
+			report("Certificate authorities", parent, DoctorFail, "%v", casError("ListCertificateAuthorities", err))
+			return false
+		}
+		authorities = append(authorities, ca)
+	}
+
+	var enabled []string
+	var expiring []string
+	for _, ca := range authorities {
+		id := path.Base(ca.GetName())
+		if ca.GetState() != casapi.CertificateAuthority_ENABLED {
+			continue
+		}
+		if target.CertificateAuthorityId != "" && id != target.CertificateAuthorityId {
+			continue
+		}
+		notAfter, ok := caExpiry(ca)
+		if !ok {
+			enabled = append(enabled, id)
+			continue
+		}
+		enabled = append(enabled, fmt.Sprintf("%s (expires %s)", id, notAfter.Format(time.DateOnly)))
+		if time.Until(notAfter) < doctorExpiryWarning {
+			expiring = append(expiring, id)
+		}
+	}
+	switch {
+	case len(enabled) == 0 && target.CertificateAuthorityId != "":
+		report("Certificate authorities", parent, DoctorFail, "CA %s does not exist or is not enabled", target.CertificateAuthorityId)
+		return false
+	case len(enabled) == 0:
+		report("Certificate authorities", parent, DoctorFail, "the CA pool has no enabled CA")
+		return false
+	case len(expiring) > 0:
+		report("Certificate authorities", parent, DoctorWarn, "enabled: %s; %s expire within 30 days", strings.Join(enabled, ", "), strings.Join(expiring, ", "))
+	default:
+		report("Certificate authorities", parent, DoctorPass, "enabled: %s", strings.Join(enabled, ", "))
+	}
+	return true
+}
+
+// caExpiry returns when the certificate of a CA expires.
+func caExpiry(ca *casapi.CertificateAuthority) (time.Time, bool) {
+	if len(ca.GetPemCaCertificates()) == 0 {
+		return time.Time{}, false
+	}
+	block, _ := pem.Decode([]byte(ca.GetPemCaCertificates()[0]))
+	if block == nil {
+		return time.Time{}, false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return cert.NotAfter, true
+}
+
+// diagnoseCertificateTemplates checks that the certificate templates of an
+// issuer exist.
+func diagnoseCertificateTemplates(ctx context.Context, casClient *privateca.CertificateAuthorityClient, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec, report doctorReport) {
+	var templates []string
+	if issuerSpec.CertificateTemplate != "" {
+		templates = append(templates, issuerSpec.CertificateTemplate)
+	}
+	for _, template := range issuerSpec.AllowedCertificateTemplates {
+		if template != issuerSpec.CertificateTemplate {
+			templates = append(templates, template)
+		}
+	}
+	if len(templates) == 0 {
+		report("Certificate templates", "", DoctorSkip, "the issuer uses no certificate template")
+		return
+	}
+
+	for _, template := range templates {
+		_, err := casClient.GetCertificateTemplate(ctx, &casapi.GetCertificateTemplateRequest{Name: template})
+		switch {
+		case status.Code(err) == codes.PermissionDenied:
+			report("Certificate templates", template, DoctorWarn, "not allowed to read the template, which needs privateca.certificateTemplates.get")
+		case err != nil:
+			report("Certificate templates", template, DoctorFail, "%v", casError("GetCertificateTemplate", err))
+		default:
+			report("Certificate templates", template, DoctorPass, "exists")
+		}
+	}
+}
+
+// diagnosePermissions checks that the issuer's credentials have the
+// permissions issuing certificates from a CA pool needs.
+func diagnosePermissions(ctx context.Context, casClient *privateca.CertificateAuthorityClient, parent string, target *issuersv1beta1.GoogleCASIssuerSpec, report doctorReport) {
+	required := issuancePermissions(target)
+	resp, err := casClient.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{
+		Resource:    parent,
+		Permissions: required,
+	})
+	if err != nil {
+		report("IAM permissions", parent, DoctorFail, "%v", casError("TestIamPermissions", err))
+		return
+	}
+	if missing := missingPermissions(required, resp.GetPermissions()); len(missing) > 0 {
+		report("IAM permissions", parent, DoctorFail, "missing %s", strings.Join(missing, ", "))
+		return
+	}
+	report("IAM permissions", parent, DoctorPass, "granted %s", strings.Join(required, ", "))
+}
+
+// diagnoseIssuance asks a CA pool to validate a request for a short-lived
+// certificate, with the issuer's template, subject mode, labels and CA,
+// without issuing it.
+func (o *GoogleCAS) diagnoseIssuance(ctx context.Context, casClient *privateca.CertificateAuthorityClient, parent string, target *issuersv1beta1.GoogleCASIssuerSpec, report doctorReport) {
+	csr, err := doctorCSR(target.SubjectMode == issuersv1beta1.SubjectModeReflectedSPIFFE)
+	if err != nil {
+		report("Dry run", parent, DoctorFail, "failed to create a certificate request: %v", err)
+		return
+	}
+	cr := signer.CertificateRequestObjectFromCertificateRequest(&cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "google-cas-issuer-doctor"},
+	})
+	labels, err := o.certificateLabels(cr, target.CertificateLabels)
+	if err != nil {
+		report("Dry run", parent, DoctorFail, "%v", err)
+		return
+	}
+
+	_, err = casClient.CreateCertificate(ctx, &casapi.CreateCertificateRequest{
+		Parent:        parent,
+		CertificateId: "google-cas-issuer-doctor-" + uuid.NewString(),
+		Certificate: &casapi.Certificate{
+			CertificateConfig:   &casapi.Certificate_PemCsr{PemCsr: string(csr)},
+			Lifetime:            durationpb.New(time.Hour),
+			CertificateTemplate: target.CertificateTemplate,
+			Labels:              labels,
+			SubjectMode:         subjectRequestModes[target.SubjectMode],
+		},
+		IssuingCertificateAuthorityId: target.CertificateAuthorityId,
+		ValidateOnly:                  true,
+	})
+	if err != nil {
+		report("Dry run", parent, DoctorFail, "%v", casError("CreateCertificate", err))
+		return
+	}
+	report("Dry run", parent, DoctorPass, "CAS accepted a validate-only certificate request")
+}
+
+// doctorCSR returns a certificate request for the dry run, for a SPIFFE ID
+// if the issuer reflects the caller's one.
+func doctorCSR(spiffe bool) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "google-cas-issuer-doctor.invalid"},
+		DNSNames: []string{"google-cas-issuer-doctor.invalid"},
+	}
+	if spiffe {
+		template = &x509.CertificateRequest{URIs: []*url.URL{{Scheme: "spiffe", Host: "google-cas-issuer-doctor.invalid", Path: "/doctor"}}}
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestDiagnose(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	spec.CertificateTemplate = fake.AddCertificateTemplate("my-project", "us-east1", "leaf")
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+
+	checks := cas.Diagnose(t.Context(), cas.client, issuer)
+
+	pool := "projects/my-project/locations/us-east1/caPools/my-pool"
+	assert.Equal(t, []DoctorCheck{
+		{Name: "Credentials", Status: DoctorPass, Message: "none, the CAS endpoint is insecure"},
+		{Name: "CA pool", Target: pool, Status: DoctorPass, Message: "exists, ENTERPRISE tier"},
+		{Name: "Certificate authorities", Target: pool, Status: DoctorPass, Message: checks[2].Message},
+		{Name: "Certificate templates", Target: spec.CertificateTemplate, Status: DoctorPass, Message: "exists"},
+		{Name: "IAM permissions", Target: pool, Status: DoctorPass, Message: "granted privateca.certificates.create"},
+		{Name: "Dry run", Target: pool, Status: DoctorPass, Message: "CAS accepted a validate-only certificate request"},
+	}, checks)
+	assert.Regexp(t, `^enabled: root-1 \(expires \d{4}-\d{2}-\d{2}\)$`, checks[2].Message)
+	assert.Empty(t, fake.Certificates(), "the dry run must not issue a certificate")
+}
+
+func TestDiagnoseFailures(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	pool := "projects/my-project/locations/us-east1/caPools/my-pool"
+	fake.SetCertificateAuthorityState(pool+"/certificateAuthorities/root-1", casapi.CertificateAuthority_DISABLED)
+	fake.SetPermissions(pool, "privateca.caPools.get")
+	spec.AllowedCertificateTemplates = []string{"projects/my-project/locations/us-east1/certificateTemplates/missing"}
+	issuer := &v1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer"}, Spec: *spec}
+
+	statuses := map[string]DoctorStatus{}
+	for _, check := range cas.Diagnose(t.Context(), nil, issuer) {
+		statuses[check.Name] = check.Status
+	}
+	assert.Equal(t, map[string]DoctorStatus{
+		"Credentials":             DoctorPass,
+		"CA pool":                 DoctorPass,
+		"Certificate authorities": DoctorFail,
+		"Certificate templates":   DoctorFail,
+		"IAM permissions":         DoctorFail,
+		"Dry run":                 DoctorSkip,
+	}, statuses)
+
+	// Without a cluster, credentials Secrets can't be read.
+	issuer.Spec.Credentials.Name = "credentials"
+	issuer.Spec.Credentials.Key = "key.json"
+	checks := cas.Diagnose(t.Context(), nil, issuer)
+	require.Len(t, checks, 1)
+	assert.Equal(t, DoctorFail, checks[0].Status)
+}
+
+func TestCredentialsPrincipal(t *testing.T) {
+	tests := map[string]struct {
+		json string
+		want string
+	}{
+		"service account key": {
+			json: `{"type": "service_account", "client_email": "issuer@my-project.iam.gserviceaccount.com"}`,
+			want: "service account issuer@my-project.iam.gserviceaccount.com",
+		},
+		"workload identity federation with impersonation": {
+			json: `{"type": "external_account", "audience": "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider", "service_account_impersonation_url": "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/issuer@my-project.iam.gserviceaccount.com:generateAccessToken"}`,
+			want: "service account issuer@my-project.iam.gserviceaccount.com through workload identity federation",
+		},
+		"workload identity federation": {
+			json: `{"type": "external_account", "audience": "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider"}`,
+			want: "federated identity of //iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider",
+		},
+		"user": {
+			json: `{"type": "authorized_user"}`,
+			want: "user account",
+		},
+		"invalid": {
+			json: `not json`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, credentialsPrincipal([]byte(tt.json)))
+		})
+	}
+}
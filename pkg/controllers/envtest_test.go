@@ -0,0 +1,325 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+	"github.com/cert-manager/google-cas-issuer/pkg/casfake"
+)
+
+const (
+	envtestTimeout  = 30 * time.Second
+	envtestInterval = 100 * time.Millisecond
+)
+
+// certManagerCRDs returns the directory holding the cert-manager CRDs in the
+// module cache.
+func certManagerCRDs(t *testing.T) string {
+	t.Helper()
+
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/cert-manager/cert-manager").Output()
+	require.NoError(t, err)
+	return filepath.Join(strings.TrimSpace(string(out)), "deploy", "crds")
+}
+
+// startEnvtest starts an API server with the issuer and cert-manager CRDs,
+// and runs the issuer controllers against a fake CAS. It skips the test
+// when the envtest binaries aren't available, see make test-unit.
+func startEnvtest(t *testing.T) (client.Client, *casfake.Server) {
+	t.Helper()
+
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, skipping envtest")
+	}
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "deploy", "crds"), certManagerCRDs(t)},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, env.Stop()) })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	require.NoError(t, err)
+
+	fake := casfake.New()
+	pool := fake.AddCaPool("my-project", "us-east1", "my-pool")
+	_, err = fake.AddCertificateAuthority(pool, "root-1", casapi.CertificateAuthority_ENABLED)
+	require.NoError(t, err)
+	addr, err := fake.Start()
+	require.NoError(t, err)
+	t.Cleanup(fake.Stop)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	require.NoError(t, (&GoogleCAS{
+		MaxRetryDuration: 30 * time.Second,
+		Endpoint:         addr,
+		InsecureEndpoint: true,
+	}).SetupWithManager(ctx, mgr, controller.Options{SkipNameValidation: ptr.To(true)}))
+
+	done := make(chan error)
+	go func() { done <- mgr.Start(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return mgr.GetClient(), fake
+}
+
+func TestControllers(t *testing.T) {
+	kubeClient, fake := startEnvtest(t)
+
+	spec := issuersv1beta1.GoogleCASIssuerSpec{
+		Project:  "my-project",
+		Location: "us-east1",
+		CaPoolId: "my-pool",
+	}
+	tests := map[string]struct {
+		issuer issuerapi.Issuer
+	}{
+		"GoogleCASIssuer": {
+			issuer: &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"}, Spec: spec},
+		},
+		"GoogleCASClusterIssuer": {
+			issuer: &issuersv1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "cluster-issuer"}, Spec: spec},
+		},
+	}
+
+	for kind, tt := range tests {
+		t.Run(kind, func(t *testing.T) {
+			require.NoError(t, kubeClient.Create(t.Context(), tt.issuer))
+			waitForIssuerReady(t, kubeClient, tt.issuer, metav1.ConditionTrue)
+
+			issuerRef := cmmetav1.IssuerReference{Group: issuersv1beta1.GroupVersion.Group, Kind: kind, Name: tt.issuer.GetName()}
+
+			cr := testCertificateRequestFor(t, "default", strings.ToLower(kind)+"-approved", issuerRef)
+			require.NoError(t, kubeClient.Create(t.Context(), cr))
+			certificatesBefore := len(fake.Certificates())
+
+			// Requests are only signed once they are approved.
+			assert.Never(t, func() bool {
+				var current cmapi.CertificateRequest
+				err := kubeClient.Get(t.Context(), client.ObjectKeyFromObject(cr), &current)
+				return err == nil && len(current.Status.Certificate) > 0
+			}, 2*time.Second, envtestInterval)
+			assert.Len(t, fake.Certificates(), certificatesBefore)
+
+			setCertificateRequestCondition(t, kubeClient, cr, cmapi.CertificateRequestConditionApproved, "Approved")
+			waitForCertificateRequestReady(t, kubeClient, cr, cmapi.CertificateRequestReasonIssued)
+
+			block, _ := pem.Decode(cr.Status.Certificate)
+			require.NotNil(t, block)
+			leaf, err := x509.ParseCertificate(block.Bytes)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"example.com"}, leaf.DNSNames)
+			assert.Equal(t, "root-1", leaf.Issuer.CommonName)
+			assert.Contains(t, string(cr.Status.CA), "BEGIN CERTIFICATE")
+			assert.Len(t, fake.Certificates(), certificatesBefore+1)
+
+			denied := testCertificateRequestFor(t, "default", strings.ToLower(kind)+"-denied", issuerRef)
+			require.NoError(t, kubeClient.Create(t.Context(), denied))
+			setCertificateRequestCondition(t, kubeClient, denied, cmapi.CertificateRequestConditionDenied, "Denied")
+			waitForCertificateRequestReady(t, kubeClient, denied, cmapi.CertificateRequestReasonDenied)
+			assert.Empty(t, denied.Status.Certificate)
+			assert.Len(t, fake.Certificates(), certificatesBefore+1)
+		})
+	}
+}
+
+func TestControllersIssuerReadiness(t *testing.T) {
+	kubeClient, fake := startEnvtest(t)
+
+	issuer := &issuersv1beta1.GoogleCASIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"},
+		Spec: issuersv1beta1.GoogleCASIssuerSpec{
+			Project:  "my-project",
+			Location: "us-east1",
+			CaPoolId: "missing-pool",
+		},
+	}
+	require.NoError(t, kubeClient.Create(t.Context(), issuer))
+	condition := waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionFalse)
+	assert.Contains(t, condition.Message, ReasonCAPoolNotFound+":")
+	assertCAPoolReady(t, issuer, metav1.ConditionFalse, ReasonCAPoolNotFound)
+
+	// issuer-lib sets the same reason on the Ready condition for every
+	// failure, so the CAPoolReady condition tells them apart.
+	pool := "projects/my-project/locations/us-east1/caPools/my-pool"
+	fake.SetPermissions(pool, "privateca.caPools.get")
+	issuer.Spec.CaPoolId = "my-pool"
+	require.NoError(t, kubeClient.Update(t.Context(), issuer))
+	missingPermissions := waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionFalse)
+	assert.Contains(t, missingPermissions.Message, ReasonMissingPermissions+":")
+	assertCAPoolReady(t, issuer, metav1.ConditionFalse, ReasonMissingPermissions)
+
+	fake.SetPermissions(pool, "privateca.certificates.create", "privateca.caPools.get")
+	waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionTrue)
+	assertCAPoolReady(t, issuer, metav1.ConditionTrue, ReasonChecked)
+}
+
+// assertCAPoolReady asserts that the CAPoolReady condition of issuer is up to
+// date, and has status and reason.
+func assertCAPoolReady(t *testing.T, issuer issuerapi.Issuer, status metav1.ConditionStatus, reason string) {
+	t.Helper()
+
+	condition := apimeta.FindStatusCondition(issuer.GetConditions(), ConditionTypeCAPoolReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, issuer.GetGeneration(), condition.ObservedGeneration)
+	assert.Equal(t, status, condition.Status)
+	assert.Equal(t, reason, condition.Reason)
+}
+
+func TestControllersCredentialsSecret(t *testing.T) {
+	kubeClient, _ := startEnvtest(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+		// The fake doesn't check credentials, only that they are there.
+		Data: map[string][]byte{"key.json": []byte("{}")},
+	}
+	require.NoError(t, kubeClient.Create(t.Context(), secret))
+	issuer := &issuersv1beta1.GoogleCASIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"},
+		Spec: issuersv1beta1.GoogleCASIssuerSpec{
+			Project:  "my-project",
+			Location: "us-east1",
+			CaPoolId: "my-pool",
+			Credentials: cmmetav1.SecretKeySelector{
+				LocalObjectReference: cmmetav1.LocalObjectReference{Name: "credentials"},
+				Key:                  "key.json",
+			},
+		},
+	}
+	require.NoError(t, kubeClient.Create(t.Context(), issuer))
+	waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionTrue)
+	generation := issuer.GetGeneration()
+
+	// Rotating the Secret re-checks the issuer, although its generation
+	// didn't change.
+	secret.Data = map[string][]byte{"other.json": []byte("{}")}
+	require.NoError(t, kubeClient.Update(t.Context(), secret))
+	condition := waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionFalse)
+	assert.Contains(t, condition.Message, ReasonCredentialsUnavailable+":")
+	assert.Equal(t, generation, issuer.GetGeneration())
+
+	secret.Data = map[string][]byte{"key.json": []byte("{}")}
+	require.NoError(t, kubeClient.Update(t.Context(), secret))
+	waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionTrue)
+
+	// So does deleting it.
+	require.NoError(t, kubeClient.Delete(t.Context(), secret))
+	condition = waitForIssuerReady(t, kubeClient, issuer, metav1.ConditionFalse)
+	assert.Contains(t, condition.Message, ReasonCredentialsUnavailable+":")
+}
+
+func testCertificateRequestFor(t *testing.T, namespace, name string, issuerRef cmmetav1.IssuerReference) *cmapi.CertificateRequest {
+	t.Helper()
+
+	return &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: cmapi.CertificateRequestSpec{
+			Request:   testCSR(t, "example.com"),
+			Duration:  &metav1.Duration{Duration: 24 * time.Hour},
+			IssuerRef: issuerRef,
+		},
+	}
+}
+
+// waitForIssuerReady waits until the Ready condition of issuer has status,
+// with an observed generation matching the issuer's, and returns it.
+func waitForIssuerReady(t *testing.T, kubeClient client.Client, issuer issuerapi.Issuer, status metav1.ConditionStatus) *metav1.Condition {
+	t.Helper()
+
+	var ready *metav1.Condition
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.NoError(c, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(issuer), issuer))
+		ready = apimeta.FindStatusCondition(issuer.GetConditions(), issuerapi.IssuerConditionTypeReady)
+		require.NotNil(c, ready)
+		assert.Equal(c, issuer.GetGeneration(), ready.ObservedGeneration)
+		assert.Equal(c, status, ready.Status)
+	}, envtestTimeout, envtestInterval)
+	return ready
+}
+
+// waitForCertificateRequestReady waits until the Ready condition of cr has
+// reason.
+func waitForCertificateRequestReady(t *testing.T, kubeClient client.Client, cr *cmapi.CertificateRequest, reason string) {
+	t.Helper()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.NoError(c, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(cr), cr))
+		for _, condition := range cr.Status.Conditions {
+			if condition.Type == cmapi.CertificateRequestConditionReady {
+				assert.Equal(c, reason, condition.Reason)
+				return
+			}
+		}
+		assert.Fail(c, "CertificateRequest has no Ready condition")
+	}, envtestTimeout, envtestInterval)
+}
+
+// setCertificateRequestCondition approves or denies cr, as an approver would.
+func setCertificateRequestCondition(t *testing.T, kubeClient client.Client, cr *cmapi.CertificateRequest, conditionType cmapi.CertificateRequestConditionType, reason string) {
+	t.Helper()
+
+	require.NoError(t, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(cr), cr))
+	now := metav1.Now()
+	cr.Status.Conditions = append(cr.Status.Conditions, cmapi.CertificateRequestCondition{
+		Type:               conditionType,
+		Status:             cmmetav1.ConditionTrue,
+		Reason:             reason,
+		Message:            reason + " by the envtest suite",
+		LastTransitionTime: &now,
+	})
+	require.NoError(t, kubeClient.Status().Update(t.Context(), cr))
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultQuotaBackoff is how long a CA pool is left alone after CAS reported
+// that its quota is exhausted without saying when to retry.
+const defaultQuotaBackoff = 30 * time.Second
+
+// casStatusError gives an error returned by the CAS API a readable message,
+// while keeping the original status available to status.Code.
+type casStatusError struct {
+	msg string
+	err error
+}
+
+func (e casStatusError) Error() string {
+	return e.msg
+}
+
+func (e casStatusError) Unwrap() error {
+	return e.err
+}
+
+// retryableError is returned for CAS errors that are expected to go away on
+// their own, such as quota exhaustion or a temporary outage. issuer-lib
+// retries them with its own backoff.
+type retryableError struct {
+	err error
+	// retryAfter is the delay CAS asked for before the next call, if any. It
+	// only backs off the CA pool, see poolBackoff.
+	retryAfter time.Duration
+	// backingOff is set if the call wasn't sent because the CA pool is
+	// backing off.
+	backingOff bool
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// nothingIssued reports whether err guarantees that no certificate was
+// issued: CAS refused the request for exhausted resources, or it wasn't sent
+// because the CA pool is backing off. After other errors, such as a timeout,
+// CAS may have issued the certificate even though the call failed.
+func nothingIssued(err error) bool {
+	var retryable retryableError
+	if !errors.As(err, &retryable) {
+		return false
+	}
+	return retryable.backingOff || status.Code(retryable.err) == codes.ResourceExhausted
+}
+
+// casError turns an error returned by the CAS API method into an error that
+// issuer-lib knows how to handle:
+//   - requests that CAS will never accept fail permanently,
+//   - missing resources and permissions mark the issuer as not ready, except
+//     for the resources a CreateCertificate call refers to, which may be
+//     specific to the request and fail it permanently, see
+//     classifyCreateFailure,
+//   - everything else is retried, and exhausted resources keep requests for
+//     the CA pool from being sent to CAS until the delay it asked for is over.
+func casError(method string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("%s failed: %w", method, err)
+	}
+
+	described := casStatusError{msg: describeCASStatus(method, st), err: err}
+
+	switch st.Code() {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return signer.PermanentError{Err: described}
+	case codes.NotFound, codes.FailedPrecondition:
+		if method == "CreateCertificate" {
+			return signer.PermanentError{Err: described}
+		}
+		return signer.IssuerError{Err: described}
+	case codes.PermissionDenied, codes.Unauthenticated, codes.Unimplemented:
+		return signer.IssuerError{Err: described}
+	case codes.ResourceExhausted:
+		retryAfter := retryDelay(st)
+		if retryAfter <= 0 {
+			retryAfter = defaultQuotaBackoff
+		}
+		return retryableError{err: described, retryAfter: retryAfter}
+	default:
+		return retryableError{err: described, retryAfter: retryDelay(st)}
+	}
+}
+
+// retryableCode reports whether casError retries CAS errors with code, as
+// opposed to failing the request or marking the issuer as not ready.
+func retryableCode(code codes.Code) bool {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange, codes.NotFound, codes.FailedPrecondition,
+		codes.PermissionDenied, codes.Unauthenticated, codes.Unimplemented:
+		return false
+	}
+	return true
+}
+
+// describeCASStatus builds a human-readable message from a CAS status,
+// including any field or precondition violations CAS reported.
+func describeCASStatus(method string, st *status.Status) string {
+	message := st.Message()
+	var violations []string
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.LocalizedMessage:
+			message = d.GetMessage()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				violations = append(violations, fmt.Sprintf("%s: %s", v.GetField(), v.GetDescription()))
+			}
+		case *errdetails.PreconditionFailure:
+			for _, v := range d.GetViolations() {
+				violations = append(violations, v.GetDescription())
+			}
+		case *errdetails.QuotaFailure:
+			for _, v := range d.GetViolations() {
+				violations = append(violations, v.GetDescription())
+			}
+		}
+	}
+
+	msg := fmt.Sprintf("Certificate Authority Service %s failed (%s): %s", method, st.Code(), message)
+	if len(violations) > 0 {
+		msg += " [" + strings.Join(violations, "; ") + "]"
+	}
+	return msg
+}
+
+// retryDelay returns the delay requested by a RetryInfo detail, or zero.
+func retryDelay(st *status.Status) time.Duration {
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.RetryInfo); ok && d.GetRetryDelay() != nil {
+			return d.GetRetryDelay().AsDuration()
+		}
+	}
+	return 0
+}
+
+// poolBackoff remembers CA pools that CAS asked us to leave alone for a while,
+// so that requests for an exhausted pool are not sent until the pool is
+// expected to accept them again. The zero value is ready to use.
+type poolBackoff struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+	now   func() time.Time
+}
+
+func (b *poolBackoff) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// check returns a retryableError if the pool is still backed off.
+func (b *poolBackoff) check(pool string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := b.until[pool].Sub(b.clock())
+	if remaining <= 0 {
+		delete(b.until, pool)
+		return nil
+	}
+	return retryableError{
+		err:        fmt.Errorf("CA pool %s is backing off after CAS reported exhausted resources", pool),
+		retryAfter: remaining,
+		backingOff: true,
+	}
+}
+
+// record backs off the pool if err reports exhausted resources.
+func (b *poolBackoff) record(pool string, err error) {
+	var retryable retryableError
+	if !errors.As(err, &retryable) || status.Code(retryable.err) != codes.ResourceExhausted {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.until == nil {
+		b.until = make(map[string]time.Time)
+	}
+	b.until[pool] = b.clock().Add(retryable.retryAfter)
+}
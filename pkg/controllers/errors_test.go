@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestCASErrorClassification(t *testing.T) {
+	tests := []struct {
+		method        string
+		code          codes.Code
+		wantPermanent bool
+		wantIssuer    bool
+		wantRetryable bool
+	}{
+		{method: "CreateCertificate", code: codes.InvalidArgument, wantPermanent: true},
+		{method: "CreateCertificate", code: codes.OutOfRange, wantPermanent: true},
+		{method: "CreateCertificate", code: codes.NotFound, wantPermanent: true},
+		{method: "CreateCertificate", code: codes.FailedPrecondition, wantPermanent: true},
+		{method: "CreateCertificate", code: codes.PermissionDenied, wantIssuer: true},
+		{method: "CreateCertificate", code: codes.Unauthenticated, wantIssuer: true},
+		{method: "CreateCertificate", code: codes.ResourceExhausted, wantRetryable: true},
+		{method: "CreateCertificate", code: codes.Unavailable, wantRetryable: true},
+		{method: "CreateCertificate", code: codes.DeadlineExceeded, wantRetryable: true},
+		{method: "CreateCertificate", code: codes.Internal, wantRetryable: true},
+		{method: "FetchCaCerts", code: codes.NotFound, wantIssuer: true},
+		{method: "FetchCaCerts", code: codes.FailedPrecondition, wantIssuer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+"/"+tt.code.String(), func(t *testing.T) {
+			err := casError(tt.method, status.Error(tt.code, "boom"))
+
+			assert.Equal(t, tt.wantPermanent, errors.As(err, &signer.PermanentError{}))
+			assert.Equal(t, tt.wantIssuer, errors.As(err, &signer.IssuerError{}))
+			assert.Equal(t, tt.wantRetryable, errors.As(err, &retryableError{}))
+			assert.Equal(t, tt.code, status.Code(err), "the original status must stay reachable")
+		})
+	}
+
+	err := casError("CreateCertificate", context.DeadlineExceeded)
+	assert.EqualError(t, err, "CreateCertificate failed: context deadline exceeded")
+}
+
+func TestCASErrorMessage(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "Request contains an invalid argument.").WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "certificate.pem_csr", Description: "SAN example.com is not allowed by the certificate template"},
+		}},
+	)
+	require.NoError(t, err)
+
+	assert.EqualError(t, casError("CreateCertificate", st.Err()),
+		"Certificate Authority Service CreateCertificate failed (InvalidArgument): Request contains an invalid argument. "+
+			"[certificate.pem_csr: SAN example.com is not allowed by the certificate template]")
+}
+
+func TestCASErrorRetryDelay(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "Quota exceeded.").WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(45 * time.Second)},
+	)
+	require.NoError(t, err)
+
+	var retryable retryableError
+	require.ErrorAs(t, casError("CreateCertificate", st.Err()), &retryable)
+	assert.Equal(t, 45*time.Second, retryable.retryAfter)
+	assert.EqualError(t, retryable, "Certificate Authority Service CreateCertificate failed (ResourceExhausted): Quota exceeded.")
+
+	require.ErrorAs(t, casError("CreateCertificate", status.Error(codes.ResourceExhausted, "Quota exceeded.")), &retryable)
+	assert.Equal(t, defaultQuotaBackoff, retryable.retryAfter)
+
+	require.ErrorAs(t, casError("CreateCertificate", status.Error(codes.Unavailable, "try again")), &retryable)
+	assert.Zero(t, retryable.retryAfter)
+}
+
+func TestPoolBackoff(t *testing.T) {
+	now := time.Now()
+	b := poolBackoff{now: func() time.Time { return now }}
+
+	const pool = "projects/p/locations/l/caPools/pool"
+	assert.NoError(t, b.check(pool))
+
+	b.record(pool, casError("CreateCertificate", status.Error(codes.Unavailable, "try again")))
+	assert.NoError(t, b.check(pool), "only exhausted resources back off the pool")
+
+	b.record(pool, casError("CreateCertificate", status.Error(codes.ResourceExhausted, "Quota exceeded.")))
+	err := b.check(pool)
+	var retryable retryableError
+	require.ErrorAs(t, err, &retryable)
+	assert.Equal(t, defaultQuotaBackoff, retryable.retryAfter)
+	assert.NoError(t, b.check("projects/p/locations/l/caPools/other"))
+
+	now = now.Add(defaultQuotaBackoff)
+	assert.NoError(t, b.check(pool))
+}
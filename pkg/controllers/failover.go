@@ -0,0 +1,185 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// ConditionTypeDegraded is set on issuers with failover targets, and is True
+// while some of their CA pools fail.
+const ConditionTypeDegraded = "Degraded"
+
+// Reasons of the Degraded condition.
+const (
+	ReasonTargetsFailing = "TargetsFailing"
+	ReasonTargetsHealthy = "TargetsHealthy"
+)
+
+// caPoolTargets returns the CA pools an issuer issues certificates from, in
+// the order they are tried: the issuer's own pool, then its failover targets.
+// Each target is a copy of issuerSpec that points at its pool.
+func caPoolTargets(issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) []*issuersv1beta1.GoogleCASIssuerSpec {
+	targets := []*issuersv1beta1.GoogleCASIssuerSpec{issuerSpec}
+	for _, failover := range issuerSpec.FailoverTargets {
+		target := *issuerSpec
+		target.Project = failover.Project
+		target.Location = failover.Location
+		target.CaPoolId = failover.CaPoolId
+		target.CertificateAuthorityId = failover.CertificateAuthorityId
+		target.FailoverTargets = nil
+		targets = append(targets, &target)
+	}
+	return targets
+}
+
+// degradedCondition returns the Degraded condition of an issuer whose failing
+// CA pools are failing.
+func degradedCondition(failing []string, generation int64) metav1.Condition {
+	if len(failing) == 0 {
+		return metav1.Condition{
+			Type:               ConditionTypeDegraded,
+			Status:             metav1.ConditionFalse,
+			Reason:             ReasonTargetsHealthy,
+			Message:            "All CA pools are healthy",
+			ObservedGeneration: generation,
+		}
+	}
+	return metav1.Condition{
+		Type:               ConditionTypeDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonTargetsFailing,
+		Message:            "Failing CA pools: " + strings.Join(failing, ", "),
+		ObservedGeneration: generation,
+	}
+}
+
+// degradedPools remembers the CA pools that each issuer with failover targets
+// last reported as failing, so that its Degraded condition is only patched
+// when they change. The zero value is ready to use.
+type degradedPools struct {
+	mu      sync.Mutex
+	issuers map[types.UID]degradedIssuer
+}
+
+type degradedIssuer struct {
+	generation int64
+	failing    []string
+}
+
+func (d *degradedPools) get(uid types.UID) (degradedIssuer, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	issuer, ok := d.issuers[uid]
+	return issuer, ok
+}
+
+func (d *degradedPools) set(uid types.UID, issuer degradedIssuer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.issuers == nil {
+		d.issuers = make(map[types.UID]degradedIssuer)
+	}
+	d.issuers[uid] = issuer
+}
+
+// reportTargets updates the Degraded condition of an issuer with failover
+// targets: the healthy CA pools are removed from the ones that were failing,
+// and the failed ones are added. A Check reports every pool, a request only
+// the pools it tried.
+//
+// The condition is only patched when the failing pools change, and failing to
+// patch it is logged; the next request or check reports the pools again.
+func (o *GoogleCAS) reportTargets(ctx context.Context, issuerObj issuerapi.Issuer, healthy, failed []string) {
+	issuerSpec, _ := o.extractIssuerSpec(issuerObj)
+	if len(issuerSpec.FailoverTargets) == 0 || o.client == nil {
+		return
+	}
+
+	previous, known := o.degraded.get(issuerObj.GetUID())
+	var failing []string
+	for _, target := range caPoolTargets(issuerSpec) {
+		parent, err := buildParentString(target)
+		if err != nil || slices.Contains(failing, parent) || slices.Contains(healthy, parent) {
+			continue
+		}
+		if slices.Contains(failed, parent) || slices.Contains(previous.failing, parent) {
+			failing = append(failing, parent)
+		}
+	}
+	current := degradedIssuer{generation: issuerObj.GetGeneration(), failing: failing}
+	if known && previous.generation == current.generation && slices.Equal(previous.failing, current.failing) {
+		return
+	}
+
+	log := ctrl.LoggerFrom(ctx)
+	if len(failing) > 0 {
+		log.Info("Issuer is degraded", "failingCAPools", failing)
+	}
+	if err := o.setIssuerCondition(ctx, issuerObj, degradedCondition(failing, current.generation)); err != nil {
+		log.V(1).Info("Failed to report failing CA pools", "error", err.Error())
+		return
+	}
+	o.degraded.set(issuerObj.GetUID(), current)
+}
+
+// setIssuerCondition sets a condition on the latest version of an issuer,
+// unless it is set already.
+func (o *GoogleCAS) setIssuerCondition(ctx context.Context, issuerObj issuerapi.Issuer, condition metav1.Condition) error {
+	latest := issuerObj.DeepCopyObject().(issuerapi.Issuer)
+	if err := o.client.Get(ctx, client.ObjectKeyFromObject(issuerObj), latest); err != nil {
+		return err
+	}
+	status := issuerStatus(latest)
+	if status == nil {
+		return nil
+	}
+
+	existing := apimeta.FindStatusCondition(status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason &&
+		existing.Message == condition.Message && existing.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+
+	patch := client.MergeFromWithOptions(latest.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	apimeta.SetStatusCondition(&status.Conditions, condition)
+	return o.client.Status().Patch(ctx, latest, patch)
+}
+
+func issuerStatus(issuerObj issuerapi.Issuer) *issuerapi.IssuerStatus {
+	switch t := issuerObj.(type) {
+	case *issuersv1beta1.GoogleCASIssuer:
+		return &t.Status
+	case *issuersv1beta1.GoogleCASClusterIssuer:
+		return &t.Status
+	}
+	return nil
+}
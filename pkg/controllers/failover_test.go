@@ -0,0 +1,208 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+const (
+	primaryPool   = "projects/my-project/locations/us-east1/caPools/my-pool"
+	secondaryPool = "projects/my-project/locations/europe-west1/caPools/my-pool"
+)
+
+func TestCAPoolTargets(t *testing.T) {
+	spec := &issuersv1beta1.GoogleCASIssuerSpec{
+		Project:                "my-project",
+		Location:               "us-east1",
+		CaPoolId:               "my-pool",
+		CertificateAuthorityId: "root-1",
+		CertificateTemplate:    "projects/my-project/locations/us-east1/certificateTemplates/leaf",
+		FailoverTargets: []issuersv1beta1.CAPoolTarget{
+			{Project: "other-project", Location: "europe-west1", CaPoolId: "other-pool"},
+		},
+	}
+
+	targets := caPoolTargets(spec)
+	require.Len(t, targets, 2)
+	assert.Same(t, spec, targets[0])
+	assert.Equal(t, "other-project", targets[1].Project)
+	assert.Equal(t, "europe-west1", targets[1].Location)
+	assert.Equal(t, "other-pool", targets[1].CaPoolId)
+	assert.Empty(t, targets[1].CertificateAuthorityId, "the CA of the primary pool isn't used for failover targets")
+	assert.Equal(t, spec.CertificateTemplate, targets[1].CertificateTemplate)
+	assert.Empty(t, targets[1].FailoverTargets)
+}
+
+func TestSignFailover(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	_, err := fake.AddCertificateAuthority(fake.AddCaPool("my-project", "europe-west1", "my-pool"), "root-eu", casapi.CertificateAuthority_ENABLED)
+	require.NoError(t, err)
+	spec.FailoverTargets = []issuersv1beta1.CAPoolTarget{{Project: "my-project", Location: "europe-west1", CaPoolId: "my-pool"}}
+	require.NoError(t, cas.client.Create(t.Context(), &issuersv1beta1.GoogleCASIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"},
+		Spec:       *spec,
+	}))
+
+	sign := func() (string, error) {
+		t.Helper()
+		var issuer issuersv1beta1.GoogleCASIssuer
+		require.NoError(t, cas.client.Get(t.Context(), client.ObjectKey{Namespace: "ns", Name: "issuer"}, &issuer))
+		cr := testCertificateRequest(t, types.UID(uuid.NewString()), "example.com")
+		if _, err := cas.Sign(t.Context(), cr, &issuer); err != nil {
+			return "", err
+		}
+		certificateID, _ := certificateIDs(cr)
+		for _, certificate := range fake.Certificates() {
+			if pool, ok := strings.CutSuffix(certificate.GetName(), "/certificates/"+certificateID); ok {
+				return pool, nil
+			}
+		}
+		t.Fatalf("certificate %s was not issued", certificateID)
+		return "", nil
+	}
+	degraded := func() *metav1.Condition {
+		t.Helper()
+		var issuer issuersv1beta1.GoogleCASIssuer
+		require.NoError(t, cas.client.Get(t.Context(), client.ObjectKey{Namespace: "ns", Name: "issuer"}, &issuer))
+		return apimeta.FindStatusCondition(issuer.Status.Conditions, ConditionTypeDegraded)
+	}
+
+	now := time.Now()
+	cas.backoff.now = func() time.Time { return now }
+
+	// An exhausted primary pool fails over to the secondary one.
+	fake.SetPoolError(primaryPool, status.Error(codes.ResourceExhausted, "quota exceeded"))
+	pool, err := sign()
+	require.NoError(t, err)
+	assert.Equal(t, secondaryPool, pool)
+	condition := degraded()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Failing CA pools: "+primaryPool, condition.Message)
+
+	// Once both pools fail, the request is retried.
+	fake.SetPoolError(secondaryPool, status.Error(codes.ResourceExhausted, "quota exceeded"))
+	_, err = sign()
+	assert.ErrorAs(t, err, &retryableError{})
+	assert.Equal(t, "Failing CA pools: "+primaryPool+", "+secondaryPool, degraded().Message)
+
+	// The secondary pool recovering doesn't clear the failure of the primary
+	// one, which it can't tell anything about.
+	fake.SetPoolError(secondaryPool, nil)
+	now = now.Add(time.Hour)
+	pool, err = sign()
+	require.NoError(t, err)
+	assert.Equal(t, secondaryPool, pool)
+	assert.Equal(t, "Failing CA pools: "+primaryPool, degraded().Message)
+
+	// Once the primary pool is back, it serves requests again.
+	fake.SetPoolError(primaryPool, nil)
+	now = now.Add(time.Hour)
+	pool, err = sign()
+	require.NoError(t, err)
+	assert.Equal(t, primaryPool, pool)
+	assert.Equal(t, metav1.ConditionFalse, degraded().Status)
+
+	// A pool that may have issued the certificate before failing is only
+	// failed over from once it's clear that it didn't.
+	fake.SetError("CreateCertificate", status.Error(codes.Aborted, "connection reset"))
+	creates, gets := fake.Calls("CreateCertificate"), fake.Calls("GetCertificate")
+	_, err = sign()
+	assert.ErrorAs(t, err, &retryableError{})
+	assert.Equal(t, creates+2, fake.Calls("CreateCertificate"), "the secondary pool is tried")
+	assert.Equal(t, gets+3, fake.Calls("GetCertificate"), "the primary pool is checked before the secondary one is tried")
+	fake.SetError("CreateCertificate", nil)
+
+	// Without being able to tell, the request is retried instead.
+	fake.SetPoolError(primaryPool, status.Error(codes.Aborted, "regional outage"))
+	creates = fake.Calls("CreateCertificate")
+	_, err = sign()
+	assert.ErrorAs(t, err, &retryableError{})
+	assert.Equal(t, creates, fake.Calls("CreateCertificate"), "the secondary pool isn't tried")
+
+	// Errors that aren't retried don't fail over.
+	fake.SetPoolError(primaryPool, status.Error(codes.InvalidArgument, "bad request"))
+	calls := fake.Calls("GetCertificate")
+	_, err = sign()
+	assert.Error(t, err)
+	assert.NotErrorAs(t, err, &retryableError{})
+	assert.Equal(t, calls+1, fake.Calls("GetCertificate"), "the secondary pool isn't tried")
+}
+
+func TestReportTargetsOnlyPatchesChanges(t *testing.T) {
+	_, cas, spec := newFakeCAS(t)
+	spec.FailoverTargets = []issuersv1beta1.CAPoolTarget{{Project: "my-project", Location: "europe-west1", CaPoolId: "my-pool"}}
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns", UID: "issuer-uid"}, Spec: *spec}
+	require.NoError(t, cas.client.Create(t.Context(), issuer))
+
+	var patches int
+	cas.client = interceptor.NewClient(cas.client.(client.WithWatch), interceptor.Funcs{
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			patches++
+			return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+		},
+	})
+
+	cas.reportTargets(t.Context(), issuer, nil, []string{primaryPool})
+	assert.Equal(t, 1, patches)
+	cas.reportTargets(t.Context(), issuer, nil, []string{primaryPool})
+	cas.reportTargets(t.Context(), issuer, []string{secondaryPool}, nil)
+	assert.Equal(t, 1, patches, "the failing pools didn't change")
+	cas.reportTargets(t.Context(), issuer, []string{primaryPool}, nil)
+	assert.Equal(t, 2, patches)
+
+	require.NoError(t, cas.client.Get(t.Context(), client.ObjectKeyFromObject(issuer), issuer))
+	assert.Equal(t, metav1.ConditionFalse, apimeta.FindStatusCondition(issuer.Status.Conditions, ConditionTypeDegraded).Status)
+}
+
+func TestCheckFailover(t *testing.T) {
+	_, cas, spec := newFakeCAS(t)
+	spec.FailoverTargets = []issuersv1beta1.CAPoolTarget{{Project: "my-project", Location: "europe-west1", CaPoolId: "my-pool"}}
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+	require.NoError(t, cas.client.Create(t.Context(), issuer))
+
+	// The issuer is Ready while one of its pools works.
+	require.NoError(t, cas.Check(t.Context(), issuer))
+	require.NoError(t, cas.client.Get(t.Context(), client.ObjectKeyFromObject(issuer), issuer))
+	condition := apimeta.FindStatusCondition(issuer.Status.Conditions, ConditionTypeDegraded)
+	require.NotNil(t, condition)
+	assert.Equal(t, ReasonTargetsFailing, condition.Reason)
+	assert.Equal(t, "Failing CA pools: "+secondaryPool, condition.Message)
+
+	// Without any working pool, it isn't.
+	issuer.Spec.CaPoolId = "missing-pool"
+	err := cas.Check(t.Context(), issuer)
+	assert.ErrorContains(t, err, ReasonCAPoolNotFound)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultEndpoint is the address of the public CAS API.
+const defaultEndpoint = "privateca.googleapis.com:443"
+
+const (
+	endpointCheckTimeout  = 2 * time.Second
+	endpointCheckCacheFor = 30 * time.Second
+)
+
+// endpointCheck caches the result of the last CAS endpoint check.
+type endpointCheck struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// CheckEndpoint is a readiness check that the CAS API endpoint accepts
+// connections. It only opens a TCP connection, without calling CAS, and its
+// result is reused for 30 seconds, so that frequent probes stay cheap.
+func (o *GoogleCAS) CheckEndpoint(req *http.Request) error {
+	o.endpointCheck.mu.Lock()
+	defer o.endpointCheck.mu.Unlock()
+
+	if !o.endpointCheck.checkedAt.IsZero() && time.Since(o.endpointCheck.checkedAt) < endpointCheckCacheFor {
+		return o.endpointCheck.err
+	}
+	o.endpointCheck.err = dialEndpoint(req.Context(), o.endpointAddress())
+	o.endpointCheck.checkedAt = time.Now()
+	return o.endpointCheck.err
+}
+
+// endpointAddress returns the host and port of the CAS API endpoint.
+func (o *GoogleCAS) endpointAddress() string {
+	if o.Endpoint == "" {
+		return defaultEndpoint
+	}
+	if _, _, err := net.SplitHostPort(o.Endpoint); err != nil {
+		return net.JoinHostPort(o.Endpoint, "443")
+	}
+	return o.Endpoint
+}
+
+func dialEndpoint(ctx context.Context, address string) error {
+	ctx, cancel := context.WithTimeout(ctx, endpointCheckTimeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("CAS endpoint %s is unreachable: %w", address, err)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointAddress(t *testing.T) {
+	assert.Equal(t, "privateca.googleapis.com:443", (&GoogleCAS{}).endpointAddress())
+	assert.Equal(t, "privateca-psc.p.googleapis.com:443", (&GoogleCAS{Endpoint: "privateca-psc.p.googleapis.com"}).endpointAddress())
+	assert.Equal(t, "127.0.0.1:8443", (&GoogleCAS{Endpoint: "127.0.0.1:8443"}).endpointAddress())
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	cas := &GoogleCAS{Endpoint: listener.Addr().String()}
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	require.NoError(t, cas.CheckEndpoint(req))
+
+	require.NoError(t, listener.Close())
+	assert.NoError(t, cas.CheckEndpoint(req), "the last result is reused for a while")
+
+	cas.endpointCheck.checkedAt = time.Now().Add(-time.Minute)
+	assert.ErrorContains(t, cas.CheckEndpoint(req), "is unreachable")
+}
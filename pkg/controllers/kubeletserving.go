@@ -0,0 +1,304 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+const (
+	nodeUserPrefix = "system:node:"
+	nodesGroup     = "system:nodes"
+)
+
+// kubeletServingUsages are the key usages a kubelet serving certificate may
+// request. Server auth and digital signature are required.
+var kubeletServingUsages = []certificatesv1.KeyUsage{
+	certificatesv1.UsageDigitalSignature,
+	certificatesv1.UsageKeyEncipherment,
+	certificatesv1.UsageServerAuth,
+}
+
+// KubeletServingSigner approves and signs kubernetes.io/kubelet-serving
+// CertificateSigningRequests through a GoogleCASClusterIssuer, so that node
+// serving certificates chain to the CAS root. A request is only approved if
+// it comes from the node it names, and only asks for that node's addresses
+// and serving key usages.
+type KubeletServingSigner struct {
+	// IssuerName is the GoogleCASClusterIssuer that signs the certificates.
+	IssuerName string
+
+	// CAS signs the certificates. It must have been set up with the manager
+	// first.
+	CAS *GoogleCAS
+
+	client client.Client
+}
+
+// SetupWithManager sets up the controller with the provided controller options
+func (s *KubeletServingSigner) SetupWithManager(mgr ctrl.Manager, ctrlOpts controller.Options) error {
+	s.client = mgr.GetClient()
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubelet-serving").
+		For(&certificatesv1.CertificateSigningRequest{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			csr, ok := obj.(*certificatesv1.CertificateSigningRequest)
+			return ok && csr.Spec.SignerName == certificatesv1.KubeletServingSignerName
+		}))).
+		WithOptions(ctrlOpts).
+		Complete(s)
+}
+
+// Reconcile verifies a kubelet serving certificate request, then approves it
+// or denies it, and signs it once it is approved.
+func (s *KubeletServingSigner) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var csr certificatesv1.CertificateSigningRequest
+	if err := s.client.Get(ctx, req.NamespacedName, &csr); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if csr.Spec.SignerName != certificatesv1.KubeletServingSignerName ||
+		len(csr.Status.Certificate) > 0 ||
+		hasCSRCondition(&csr, certificatesv1.CertificateDenied) ||
+		hasCSRCondition(&csr, certificatesv1.CertificateFailed) {
+		return ctrl.Result{}, nil
+	}
+
+	approved := hasCSRCondition(&csr, certificatesv1.CertificateApproved)
+
+	if err := s.verify(ctx, &csr); err != nil {
+		var invalid invalidKubeletServingRequest
+		if !errors.As(err, &invalid) {
+			return ctrl.Result{}, err
+		}
+		log.Info("Refusing kubelet serving certificate request", "reason", err.Error())
+		if approved {
+			// Someone else approved a request we won't sign.
+			return ctrl.Result{}, s.fail(ctx, &csr, "InvalidRequest", err.Error())
+		}
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:           certificatesv1.CertificateDenied,
+			Status:         corev1.ConditionTrue,
+			Reason:         "InvalidRequest",
+			Message:        err.Error(),
+			LastUpdateTime: metav1.Now(),
+		})
+		return ctrl.Result{}, s.client.SubResource("approval").Update(ctx, &csr)
+	}
+
+	if !approved {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:           certificatesv1.CertificateApproved,
+			Status:         corev1.ConditionTrue,
+			Reason:         "AutoApproved",
+			Message:        fmt.Sprintf("Auto approved by the %s GoogleCASClusterIssuer", s.IssuerName),
+			LastUpdateTime: metav1.Now(),
+		})
+		// Approving the request updates it, which triggers signing it.
+		return ctrl.Result{}, s.client.SubResource("approval").Update(ctx, &csr)
+	}
+
+	var issuer issuersv1beta1.GoogleCASClusterIssuer
+	if err := s.client.Get(ctx, types.NamespacedName{Name: s.IssuerName}, &issuer); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get GoogleCASClusterIssuer %s: %w", s.IssuerName, err)
+	}
+	ready := apimeta.FindStatusCondition(issuer.GetConditions(), issuerapi.IssuerConditionTypeReady)
+	if ready == nil || ready.Status != metav1.ConditionTrue || ready.ObservedGeneration != issuer.Generation {
+		return ctrl.Result{}, fmt.Errorf("GoogleCASClusterIssuer %s is not ready", s.IssuerName)
+	}
+
+	bundle, err := s.CAS.Sign(ctx, signer.CertificateRequestObjectFromCertificateSigningRequest(&csr), &issuer)
+	if errors.As(err, &signer.PermanentError{}) {
+		return ctrl.Result{}, s.fail(ctx, &csr, "SigningFailed", err.Error())
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	patch := client.MergeFrom(csr.DeepCopy())
+	csr.Status.Certificate = bundle.ChainPEM
+	return ctrl.Result{}, s.client.Status().Patch(ctx, &csr, patch)
+}
+
+// fail marks csr as Failed, so that it isn't retried.
+func (s *KubeletServingSigner) fail(ctx context.Context, csr *certificatesv1.CertificateSigningRequest, reason, message string) error {
+	patch := client.MergeFrom(csr.DeepCopy())
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:           certificatesv1.CertificateFailed,
+		Status:         corev1.ConditionTrue,
+		Reason:         reason,
+		Message:        message,
+		LastUpdateTime: metav1.Now(),
+	})
+	return s.client.Status().Patch(ctx, csr, patch)
+}
+
+// invalidKubeletServingRequest is returned for requests that must not be
+// signed.
+type invalidKubeletServingRequest struct {
+	msg string
+}
+
+func (e invalidKubeletServingRequest) Error() string {
+	return e.msg
+}
+
+func invalidf(format string, args ...any) error {
+	return invalidKubeletServingRequest{msg: fmt.Sprintf(format, args...)}
+}
+
+// verify checks that csr was requested by a node for its own addresses, see
+// validateKubeletServingRequest.
+func (s *KubeletServingSigner) verify(ctx context.Context, csr *certificatesv1.CertificateSigningRequest) error {
+	nodeName, ok := strings.CutPrefix(csr.Spec.Username, nodeUserPrefix)
+	if !ok || nodeName == "" || !slices.Contains(csr.Spec.Groups, nodesGroup) {
+		return invalidf("requester %q is not a node", csr.Spec.Username)
+	}
+
+	var node corev1.Node
+	if err := s.client.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return invalidf("node %s does not exist", nodeName)
+		}
+		return err
+	}
+
+	return validateKubeletServingRequest(csr, &node)
+}
+
+// validateKubeletServingRequest checks that csr asks for a serving certificate
+// for node, with the subject a kubelet uses, only SANs that are addresses of
+// node, and only serving key usages.
+func validateKubeletServingRequest(csr *certificatesv1.CertificateSigningRequest, node *corev1.Node) error {
+	for _, usage := range csr.Spec.Usages {
+		if !slices.Contains(kubeletServingUsages, usage) {
+			return invalidf("key usage %q is not allowed", usage)
+		}
+	}
+	for _, usage := range []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageServerAuth} {
+		if !slices.Contains(csr.Spec.Usages, usage) {
+			return invalidf("key usage %q is required", usage)
+		}
+	}
+
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return invalidf("request is not a PEM encoded certificate request")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return invalidf("failed to parse certificate request: %v", err)
+	}
+	if err := req.CheckSignature(); err != nil {
+		return invalidf("invalid certificate request signature: %v", err)
+	}
+
+	// CAS copies the CSR's extensions into the certificate, so they must not
+	// ask for more than a serving certificate either.
+	requested, err := requestedExtensions(req)
+	if err != nil {
+		return invalidf("%v", err)
+	}
+	if requested.isCA {
+		return invalidf("CA certificates are not allowed")
+	}
+	if forbidden := requested.keyUsage &^ (x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment); forbidden != 0 {
+		return invalidf("key usages %q are not allowed", keyUsageNames(forbidden))
+	}
+	for _, usage := range requested.extKeyUsage {
+		if usage != x509.ExtKeyUsageServerAuth {
+			return invalidf("key usage %q is not allowed", extKeyUsageName(usage))
+		}
+	}
+	if len(requested.unknownExtKeyUsage) > 0 {
+		return invalidf("extended key usage %s is not allowed", requested.unknownExtKeyUsage[0])
+	}
+
+	if want := nodeUserPrefix + node.Name; req.Subject.CommonName != want {
+		return invalidf("common name %q must be %q", req.Subject.CommonName, want)
+	}
+	if !slices.Equal(req.Subject.Organization, []string{nodesGroup}) {
+		return invalidf("organization %q must be [%q]", req.Subject.Organization, nodesGroup)
+	}
+	// Checked on the raw extension, as crypto/x509 drops the SANs it can't
+	// parse.
+	for _, tag := range requested.subjectAltNameTags {
+		if tag != sanTagDNS && tag != sanTagIP {
+			return invalidf("%s SANs are not allowed", sanTagName(tag))
+		}
+	}
+	if len(req.DNSNames) == 0 && len(req.IPAddresses) == 0 {
+		return invalidf("at least one DNS or IP SAN is required")
+	}
+
+	var dnsNames []string
+	var ips []net.IP
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeHostName, corev1.NodeInternalDNS, corev1.NodeExternalDNS:
+			dnsNames = append(dnsNames, address.Address)
+		case corev1.NodeInternalIP, corev1.NodeExternalIP:
+			if ip := net.ParseIP(address.Address); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	for _, dnsName := range req.DNSNames {
+		if !slices.Contains(dnsNames, dnsName) {
+			return invalidf("DNS SAN %q is not an address of node %s", dnsName, node.Name)
+		}
+	}
+	for _, ip := range req.IPAddresses {
+		if !slices.ContainsFunc(ips, ip.Equal) {
+			return invalidf("IP SAN %q is not an address of node %s", ip, node.Name)
+		}
+	}
+	return nil
+}
+
+func hasCSRCondition(csr *certificatesv1.CertificateSigningRequest, conditionType certificatesv1.RequestConditionType) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,399 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func testNode() *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: "node-1"},
+			{Type: corev1.NodeInternalDNS, Address: "node-1.c.my-project.internal"},
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+}
+
+func testKubeletServingCSR(t *testing.T, template *x509.CertificateRequest) *certificatesv1.CertificateSigningRequest {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	require.NoError(t, err)
+
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "csr-1", UID: "3f6c3a4e-58b5-4f1d-9d2e-8a1c7b0e5f42"},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:           pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+			SignerName:        certificatesv1.KubeletServingSignerName,
+			ExpirationSeconds: ptr.To[int32](86400),
+			Username:          "system:node:node-1",
+			Groups:            []string{"system:nodes", "system:authenticated"},
+			Usages:            []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageServerAuth},
+		},
+	}
+}
+
+func validKubeletServingTemplate() *x509.CertificateRequest {
+	return &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "system:node:node-1", Organization: []string{"system:nodes"}},
+		DNSNames:    []string{"node-1", "node-1.c.my-project.internal"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+	}
+}
+
+func TestValidateKubeletServingRequest(t *testing.T) {
+	withExtension := func(id asn1.ObjectIdentifier, value any) *x509.CertificateRequest {
+		der, err := asn1.Marshal(value)
+		require.NoError(t, err)
+		template := validKubeletServingTemplate()
+		template.ExtraExtensions = []pkix.Extension{{Id: id, Value: der}}
+		return template
+	}
+
+	tests := map[string]struct {
+		template *x509.CertificateRequest
+		mutate   func(csr *certificatesv1.CertificateSigningRequest)
+		wantErr  string
+	}{
+		"valid": {
+			template: validKubeletServingTemplate(),
+		},
+		"key encipherment is allowed": {
+			template: validKubeletServingTemplate(),
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Usages = append(csr.Spec.Usages, certificatesv1.UsageKeyEncipherment)
+			},
+		},
+		"client auth": {
+			template: validKubeletServingTemplate(),
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Usages = append(csr.Spec.Usages, certificatesv1.UsageClientAuth)
+			},
+			wantErr: `key usage "client auth" is not allowed`,
+		},
+		"missing server auth": {
+			template: validKubeletServingTemplate(),
+			mutate: func(csr *certificatesv1.CertificateSigningRequest) {
+				csr.Spec.Usages = []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature}
+			},
+			wantErr: `key usage "server auth" is required`,
+		},
+		"CA in the CSR": {
+			template: withExtension(oidExtensionBasicConstraints, struct{ IsCA bool }{true}),
+			wantErr:  "CA certificates are not allowed",
+		},
+		"serving key usages in the CSR": {
+			// Digital signature and key encipherment.
+			template: withExtension(oidExtensionKeyUsage, asn1.BitString{Bytes: []byte{0xa0}, BitLength: 3}),
+		},
+		"cert sign in the CSR": {
+			// Digital signature and cert sign.
+			template: withExtension(oidExtensionKeyUsage, asn1.BitString{Bytes: []byte{0x84}, BitLength: 6}),
+			wantErr:  `key usages ["cert sign"] are not allowed`,
+		},
+		"server auth in the CSR": {
+			template: withExtension(oidExtensionExtKeyUsage, []asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 1}}),
+		},
+		"client auth in the CSR": {
+			template: withExtension(oidExtensionExtKeyUsage, []asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 1}, {1, 3, 6, 1, 5, 5, 7, 3, 2}}),
+			wantErr:  `key usage "client auth" is not allowed`,
+		},
+		"unknown extended key usage in the CSR": {
+			template: withExtension(oidExtensionExtKeyUsage, []asn1.ObjectIdentifier{{1, 2, 3, 4}}),
+			wantErr:  "extended key usage 1.2.3.4 is not allowed",
+		},
+		"email SAN": {
+			template: &x509.CertificateRequest{
+				Subject:        pkix.Name{CommonName: "system:node:node-1", Organization: []string{"system:nodes"}},
+				DNSNames:       []string{"node-1"},
+				EmailAddresses: []string{"admin@example.com"},
+			},
+			wantErr: "email SANs are not allowed",
+		},
+		"otherName SAN in the CSR": {
+			template: withExtension(oidExtensionSubjectAltName, subjectAltNamesWithUPN(t, "node-1", "admin@example.com")),
+			wantErr:  "otherName SANs are not allowed",
+		},
+		"another node's common name": {
+			template: &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "system:node:node-2", Organization: []string{"system:nodes"}},
+				DNSNames: []string{"node-1"},
+			},
+			wantErr: `common name "system:node:node-2" must be "system:node:node-1"`,
+		},
+		"wrong organization": {
+			template: &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "system:node:node-1", Organization: []string{"system:masters"}},
+				DNSNames: []string{"node-1"},
+			},
+			wantErr: `organization ["system:masters"] must be ["system:nodes"]`,
+		},
+		"no SANs": {
+			template: &x509.CertificateRequest{
+				Subject: pkix.Name{CommonName: "system:node:node-1", Organization: []string{"system:nodes"}},
+			},
+			wantErr: "at least one DNS or IP SAN is required",
+		},
+		"DNS name of another host": {
+			template: &x509.CertificateRequest{
+				Subject:  pkix.Name{CommonName: "system:node:node-1", Organization: []string{"system:nodes"}},
+				DNSNames: []string{"kubernetes.default.svc"},
+			},
+			wantErr: `DNS SAN "kubernetes.default.svc" is not an address of node node-1`,
+		},
+		"IP of another host": {
+			template: &x509.CertificateRequest{
+				Subject:     pkix.Name{CommonName: "system:node:node-1", Organization: []string{"system:nodes"}},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.2")},
+			},
+			wantErr: `IP SAN "10.0.0.2" is not an address of node node-1`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr := testKubeletServingCSR(t, tt.template)
+			if tt.mutate != nil {
+				tt.mutate(csr)
+			}
+
+			err := validateKubeletServingRequest(csr, testNode())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+			assert.ErrorAs(t, err, &invalidKubeletServingRequest{})
+		})
+	}
+}
+
+func TestKubeletServingSigner(t *testing.T) {
+	casServer, cas, spec := newFakeCAS(t)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuersv1beta1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, certificatesv1.AddToScheme(scheme))
+
+	issuer := &issuersv1beta1.GoogleCASClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodes", Generation: 1},
+		Spec:       *spec,
+		Status: issuerapi.IssuerStatus{Conditions: []metav1.Condition{{
+			Type:               issuerapi.IssuerConditionTypeReady,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 1,
+		}}},
+	}
+	valid := testKubeletServingCSR(t, validKubeletServingTemplate())
+	impostor := testKubeletServingCSR(t, validKubeletServingTemplate())
+	impostor.Name = "csr-2"
+	impostor.Spec.Username = "system:node:node-2"
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&certificatesv1.CertificateSigningRequest{}).
+		WithObjects(issuer, testNode(), valid, impostor).
+		Build()
+	// The signer only gets the access the chart grants it.
+	role := chartClusterRole(t, map[string]any{"kubeletServing": map[string]any{"issuer": "nodes"}})
+	s := &KubeletServingSigner{IssuerName: "nodes", CAS: cas, client: interceptor.NewClient(kubeClient, rbacFuncs(role, scheme))}
+
+	reconcileCSR := func(name string) *certificatesv1.CertificateSigningRequest {
+		t.Helper()
+		_, err := s.Reconcile(t.Context(), reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		require.NoError(t, err)
+		var csr certificatesv1.CertificateSigningRequest
+		require.NoError(t, kubeClient.Get(t.Context(), types.NamespacedName{Name: name}, &csr))
+		return &csr
+	}
+
+	// The first reconcile approves the request, the second signs it.
+	csr := reconcileCSR("csr-1")
+	assert.True(t, hasCSRCondition(csr, certificatesv1.CertificateApproved))
+	assert.Empty(t, csr.Status.Certificate)
+
+	csr = reconcileCSR("csr-1")
+	block, _ := pem.Decode(csr.Status.Certificate)
+	require.NotNil(t, block)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, "system:node:node-1", leaf.Subject.CommonName)
+	assert.Equal(t, "root-1", leaf.Issuer.CommonName)
+	assert.Len(t, casServer.Certificates(), 1)
+
+	// Requests for another node's identity are denied without being signed.
+	csr = reconcileCSR("csr-2")
+	assert.True(t, hasCSRCondition(csr, certificatesv1.CertificateDenied))
+	assert.Empty(t, csr.Status.Certificate)
+	assert.Len(t, casServer.Certificates(), 1)
+}
+
+func TestChartClusterRoleSigners(t *testing.T) {
+	role := chartClusterRole(t, map[string]any{"kubeletServing": map[string]any{"issuer": "nodes"}})
+
+	assert.True(t, roleAllows(role, "approve", certificatesv1.GroupName, "signers", certificatesv1.KubeletServingSignerName))
+	assert.True(t, roleAllows(role, "sign", certificatesv1.GroupName, "signers", certificatesv1.KubeletServingSignerName))
+}
+
+// chartClusterRole renders the controller's ClusterRole from the Helm chart,
+// with the optional features off unless app enables them.
+func chartClusterRole(t *testing.T, app map[string]any) *rbacv1.ClusterRole {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "deploy", "charts", "google-cas-issuer", "templates", "clusterrole.yaml"))
+	require.NoError(t, err)
+	tmpl, err := template.New("clusterrole").Funcs(template.FuncMap{
+		"include": func(name string, _ any) string {
+			if strings.HasSuffix(name, ".labels") {
+				return ""
+			}
+			return "cert-manager-google-cas-issuer"
+		},
+		"indent": func(n int, s string) string {
+			return strings.Repeat(" ", n) + s
+		},
+	}).Parse(string(raw))
+	require.NoError(t, err)
+
+	values := map[string]any{
+		"kubeletServing":             map[string]any{"issuer": ""},
+		"approval":                   map[string]any{"enabled": false},
+		"workloadIdentityFederation": map[string]any{"enabled": false},
+		"trustBundles":               map[string]any{"enabled": false, "namespace": ""},
+	}
+	maps.Copy(values, app)
+	var out strings.Builder
+	require.NoError(t, tmpl.Execute(&out, map[string]any{"Values": map[string]any{"app": values}}))
+	doc, _, _ := strings.Cut(out.String(), "\n---")
+
+	var role rbacv1.ClusterRole
+	require.NoError(t, yaml.UnmarshalStrict([]byte(doc), &role))
+	require.NotEmpty(t, role.Rules)
+	return &role
+}
+
+// roleAllows reports whether role grants verb on the named resource. Only
+// exact matches count, like the API server does for everything but signers.
+func roleAllows(role *rbacv1.ClusterRole, verb, group, resource, name string) bool {
+	return slices.ContainsFunc(role.Rules, func(rule rbacv1.PolicyRule) bool {
+		return slices.Contains(rule.Verbs, verb) &&
+			slices.Contains(rule.APIGroups, group) &&
+			slices.Contains(rule.Resources, resource) &&
+			(len(rule.ResourceNames) == 0 || slices.Contains(rule.ResourceNames, name))
+	})
+}
+
+// rbacFuncs forbids any request that role does not grant.
+func rbacFuncs(role *rbacv1.ClusterRole, scheme *runtime.Scheme) interceptor.Funcs {
+	check := func(obj client.Object, verb, subresource string) error {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return err
+		}
+		// The kinds used here all pluralise by adding an s.
+		resource := strings.ToLower(gvk.Kind) + "s"
+		if subresource != "" {
+			resource += "/" + subresource
+		}
+		if !roleAllows(role, verb, gvk.Group, resource, obj.GetName()) {
+			return apierrors.NewForbidden(schema.GroupResource{Group: gvk.Group, Resource: resource}, obj.GetName(), errors.New("not granted by the chart's ClusterRole"))
+		}
+		return nil
+	}
+
+	return interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := check(obj, "get", ""); err != nil {
+				return err
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if err := check(obj, "create", ""); err != nil {
+				return err
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if err := check(obj, "update", ""); err != nil {
+				return err
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if err := check(obj, "patch", ""); err != nil {
+				return err
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			if err := check(obj, "update", subResource); err != nil {
+				return err
+			}
+			return c.SubResource(subResource).Update(ctx, obj, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			if err := check(obj, "patch", subResource); err != nil {
+				return err
+			}
+			return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+		},
+	}
+}
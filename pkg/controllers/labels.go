@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+)
+
+// maxLabelLength is the maximum length of CAS label values.
+const maxLabelLength = 63
+
+// labelData is what CertificateLabels templates are executed with.
+type labelData struct {
+	ClusterName string
+	Namespace   string
+	Name        string
+	Annotations map[string]string
+}
+
+// parseLabelTemplate parses a CertificateLabels value. Missing annotations
+// render as empty strings.
+func parseLabelTemplate(key, value string) (*template.Template, error) {
+	return template.New(key).Option("missingkey=zero").Parse(value)
+}
+
+// certificateLabels renders the CertificateLabels of an issuer for cr.
+func (o *GoogleCAS) certificateLabels(cr signer.CertificateRequestObject, labels map[string]string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	data := labelData{
+		ClusterName: o.ClusterName,
+		Namespace:   cr.GetNamespace(),
+		Name:        cr.GetName(),
+		Annotations: cr.GetAnnotations(),
+	}
+	rendered := make(map[string]string, len(labels))
+	for key, value := range labels {
+		tmpl, err := parseLabelTemplate(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate label %s: %w", key, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render certificate label %s: %w", key, err)
+		}
+		rendered[key] = sanitizeLabelValue(b.String())
+	}
+	return rendered, nil
+}
+
+func validLabelRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
+
+// sanitizeLabelValue makes value a valid CAS label value: it is lowercased,
+// other characters than letters, digits, '_' and '-' are replaced with '_',
+// and it is truncated to 63 characters.
+func sanitizeLabelValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if validLabelRune(r) {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(value))
+	if len(value) > maxLabelLength {
+		value = value[:maxLabelLength]
+	}
+	return value
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	assert.Equal(t, "my-cluster_eu_1", sanitizeLabelValue("My-Cluster.EU/1"))
+	assert.Equal(t, "", sanitizeLabelValue(""))
+	assert.Equal(t, strings.Repeat("a", 63), sanitizeLabelValue(strings.Repeat("a", 100)))
+}
+
+func TestSignCertificateLabels(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	cas.ClusterName = "prod-eu"
+	spec.CertificateLabels = map[string]string{
+		"team":        "payments",
+		"cluster":     "{{ .ClusterName }}",
+		"namespace":   "{{ .Namespace }}",
+		"request":     "{{ .Name }}",
+		"certificate": `{{ index .Annotations "cert-manager.io/certificate-name" }}`,
+		"missing":     `{{ index .Annotations "example.com/missing" }}`,
+	}
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+	cr := testCertificateRequest(t, "0b8c4f5e-3f0e-4d7a-9a43-6f1c2b7e9d10", "example.com")
+	cr.SetAnnotations(map[string]string{cmapi.CertificateNameKey: "Web.Example"})
+
+	_, err := cas.Sign(t.Context(), cr, issuer)
+	require.NoError(t, err)
+
+	require.Len(t, fake.Certificates(), 1)
+	assert.Equal(t, map[string]string{
+		"team":        "payments",
+		"cluster":     "prod-eu",
+		"namespace":   "ns",
+		"request":     "cr",
+		"certificate": "web_example",
+		"missing":     "",
+	}, fake.Certificates()[0].GetLabels())
+}
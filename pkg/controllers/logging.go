@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Log keys, used by every controller so that the log lines of an issuer,
+// request or CAS certificate can be found with the same query. Objects are
+// logged with klog.KObj, as "namespace/name" in text and as an object with
+// name and namespace in JSON.
+const (
+	logKeyIssuerKind         = "issuerKind"
+	logKeyIssuer             = "issuer"
+	logKeyCertificateRequest = "certificateRequest"
+	logKeyCASCertificate     = "casCertificate"
+	logKeyCAPool             = "caPool"
+)
+
+// withIssuerLogger returns ctx with a logger that logs the issuer's keys.
+func withIssuerLogger(ctx context.Context, issuerObj issuerapi.Issuer, keysAndValues ...any) context.Context {
+	log := ctrl.LoggerFrom(ctx).WithValues(logKeyIssuerKind, issuerKind(issuerObj), logKeyIssuer, klog.KObj(issuerObj))
+	if len(keysAndValues) > 0 {
+		log = log.WithValues(keysAndValues...)
+	}
+	return ctrl.LoggerInto(ctx, log)
+}
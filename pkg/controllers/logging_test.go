@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestSignLogKeys(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	issuer := &v1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer"}, Spec: *spec}
+
+	var lines []string
+	log := funcr.NewJSON(func(obj string) { lines = append(lines, obj) }, funcr.Options{Verbosity: 1})
+	ctx := ctrl.LoggerInto(t.Context(), log)
+
+	cr := testCertificateRequest(t, "0c6f3f9e-5d1b-4f57-8f0e-2c7a9e6b1d42", "example.com")
+	_, err := cas.Sign(ctx, cr, issuer)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, lines)
+	assert.Contains(t, lines[0], `"msg":"Issued CAS certificate"`)
+	assert.Contains(t, lines[0], `"issuerKind":"GoogleCASClusterIssuer"`)
+	assert.Contains(t, lines[0], `"issuer":{"name":"issuer"}`)
+	assert.Contains(t, lines[0], `"certificateRequest":{"name":"`+cr.GetName()+`","namespace":"`+cr.GetNamespace()+`"}`)
+	assert.Contains(t, lines[0], `"casCertificate":"`+fake.Certificates()[0].GetName()+`"`)
+	assert.Contains(t, lines[0], `"caPool":"projects/my-project/locations/us-east1/caPools/my-pool"`)
+}
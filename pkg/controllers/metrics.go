@@ -0,0 +1,148 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"path"
+	"time"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+const metricsNamespace = "google_cas_issuer"
+
+// Issuance outcomes reported by the certificate_issuances_total metric.
+const (
+	outcomeSuccess        = "success"
+	outcomePermanentError = "permanent_error"
+	outcomeIssuerError    = "issuer_error"
+	outcomeRetryableError = "retryable_error"
+	outcomeError          = "error"
+)
+
+var (
+	casRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "cas_requests_total",
+		Help:      "Number of requests made to the Certificate Authority Service API, by method and gRPC status code.",
+	}, []string{"method", "code"})
+
+	casRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "cas_request_duration_seconds",
+		Help:      "Latency of requests made to the Certificate Authority Service API, by method and gRPC status code.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "code"})
+
+	issuancesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "certificate_issuances_total",
+		Help:      "Number of attempts to sign a certificate, by issuer, CA pool and outcome.",
+	}, []string{"issuer_kind", "issuer_namespace", "issuer_name", "ca_pool", "outcome"})
+
+	issuedCertificateLifetime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "issued_certificate_lifetime_seconds",
+		Help:      "Lifetime of the certificates issued by CAS, by CA pool.",
+		Buckets: []float64{
+			(time.Hour).Seconds(),
+			(6 * time.Hour).Seconds(),
+			(24 * time.Hour).Seconds(),
+			(7 * 24 * time.Hour).Seconds(),
+			(30 * 24 * time.Hour).Seconds(),
+			(90 * 24 * time.Hour).Seconds(),
+			(365 * 24 * time.Hour).Seconds(),
+		},
+	}, []string{"ca_pool"})
+
+	timeToSigned = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "certificate_request_time_to_signed_seconds",
+		Help:      "Time from the creation of a certificate request until it was signed, by CA pool.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 300, 900, 3600},
+	}, []string{"ca_pool"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		casRequestsTotal,
+		casRequestDuration,
+		issuancesTotal,
+		issuedCertificateLifetime,
+		timeToSigned,
+	)
+}
+
+// casMetricsInterceptor records the count and latency of every CAS API call.
+func casMetricsInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	labels := prometheus.Labels{"method": path.Base(method), "code": status.Code(err).String()}
+	casRequestsTotal.With(labels).Inc()
+	casRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// observeIssuance records the outcome of signing cr with an issuer.
+func observeIssuance(cr signer.CertificateRequestObject, issuerObj issuerapi.Issuer, pool string, bundle signer.PEMBundle, err error) {
+	issuancesTotal.WithLabelValues(issuerKind(issuerObj), issuerObj.GetNamespace(), issuerObj.GetName(), pool, issuanceOutcome(err)).Inc()
+
+	if err != nil {
+		return
+	}
+
+	timeToSigned.WithLabelValues(pool).Observe(time.Since(cr.GetCreationTimestamp().Time).Seconds())
+	if block, _ := pem.Decode(bundle.ChainPEM); block != nil {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			issuedCertificateLifetime.WithLabelValues(pool).Observe(cert.NotAfter.Sub(cert.NotBefore).Seconds())
+		}
+	}
+}
+
+func issuerKind(issuerObj issuerapi.Issuer) string {
+	if _, ok := issuerObj.(*issuersv1beta1.GoogleCASClusterIssuer); ok {
+		return "GoogleCASClusterIssuer"
+	}
+	return "GoogleCASIssuer"
+}
+
+func issuanceOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.As(err, &signer.PermanentError{}):
+		return outcomePermanentError
+	case errors.As(err, &signer.IssuerError{}):
+		return outcomeIssuerError
+	case errors.As(err, &retryableError{}):
+		return outcomeRetryableError
+	default:
+		return outcomeError
+	}
+}
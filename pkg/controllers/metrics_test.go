@@ -0,0 +1,95 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestIssuanceOutcome(t *testing.T) {
+	assert.Equal(t, outcomeSuccess, issuanceOutcome(nil))
+	assert.Equal(t, outcomePermanentError, issuanceOutcome(casError("CreateCertificate", status.Error(codes.InvalidArgument, ""))))
+	assert.Equal(t, outcomeIssuerError, issuanceOutcome(casError("CreateCertificate", status.Error(codes.PermissionDenied, ""))))
+	assert.Equal(t, outcomeRetryableError, issuanceOutcome(casError("CreateCertificate", status.Error(codes.Unavailable, ""))))
+	assert.Equal(t, outcomeError, issuanceOutcome(errors.New("boom")))
+}
+
+func TestCASMetricsInterceptor(t *testing.T) {
+	const method = "/google.cloud.security.privateca.v1.CertificateAuthorityService/CreateCertificate"
+	invoker := func(err error) grpc.UnaryInvoker {
+		return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error { return err }
+	}
+
+	ok := casRequestsTotal.WithLabelValues("CreateCertificate", "OK")
+	unavailable := casRequestsTotal.WithLabelValues("CreateCertificate", "Unavailable")
+	okBefore, unavailableBefore := testutil.ToFloat64(ok), testutil.ToFloat64(unavailable)
+
+	assert.NoError(t, casMetricsInterceptor(t.Context(), method, nil, nil, nil, invoker(nil)))
+	err := casMetricsInterceptor(t.Context(), method, nil, nil, nil, invoker(status.Error(codes.Unavailable, "try again")))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	assert.Equal(t, okBefore+1, testutil.ToFloat64(ok))
+	assert.Equal(t, unavailableBefore+1, testutil.ToFloat64(unavailable))
+}
+
+func TestObserveIssuance(t *testing.T) {
+	const pool = "projects/p/locations/l/caPools/metrics-test"
+
+	cr := signer.CertificateRequestObjectFromCertificateRequest(&cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Minute))},
+	})
+	issuer := &issuersv1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "cluster-issuer"}}
+	leaf := generateTestCert(t, false, "leaf", "ca", time.Now().Add(23*time.Hour), nil)
+
+	success := issuancesTotal.WithLabelValues("GoogleCASClusterIssuer", "", "cluster-issuer", pool, outcomeSuccess)
+	permanentError := issuancesTotal.WithLabelValues("GoogleCASClusterIssuer", "", "cluster-issuer", pool, outcomePermanentError)
+	successBefore, permanentErrorBefore := testutil.ToFloat64(success), testutil.ToFloat64(permanentError)
+	timedBefore, lifetimesBefore := sampleCount(t, timeToSigned, pool), sampleCount(t, issuedCertificateLifetime, pool)
+
+	observeIssuance(cr, issuer, pool, signer.PEMBundle{ChainPEM: []byte(leaf)}, nil)
+	observeIssuance(cr, issuer, pool, signer.PEMBundle{}, casError("CreateCertificate", status.Error(codes.InvalidArgument, "")))
+
+	assert.Equal(t, successBefore+1, testutil.ToFloat64(success))
+	assert.Equal(t, permanentErrorBefore+1, testutil.ToFloat64(permanentError))
+	assert.Equal(t, timedBefore+1, sampleCount(t, timeToSigned, pool), "only signed requests are timed")
+	assert.Equal(t, lifetimesBefore+1, sampleCount(t, issuedCertificateLifetime, pool))
+}
+
+func sampleCount(t *testing.T, histogram *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	require.NoError(t, histogram.WithLabelValues(labels...).(prometheus.Histogram).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,391 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// policyViolation is returned for requests that an issuer's policy refuses.
+type policyViolation struct {
+	msg string
+}
+
+func (e policyViolation) Error() string {
+	return "request violates the issuer's policy: " + e.msg
+}
+
+func violationf(format string, args ...any) error {
+	return policyViolation{msg: fmt.Sprintf(format, args...)}
+}
+
+// checkPolicy checks a request against the policy of its issuer, so that
+// requests the policy refuses are never sent to CAS.
+func checkPolicy(policy *issuersv1beta1.IssuancePolicy, details signer.CertificateDetails) error {
+	if policy == nil {
+		return nil
+	}
+
+	block, _ := pem.Decode(details.CSR)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return errors.New("request is not a PEM encoded certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate request: %w", err)
+	}
+
+	// CAS signs the CSR, so the extensions it asks for count as much as the
+	// CertificateRequest's spec.
+	requested, err := requestedExtensions(csr)
+	if err != nil {
+		return err
+	}
+	isCA := details.IsCA || requested.isCA
+	keyUsage := details.KeyUsage | requested.keyUsage
+	extKeyUsage := append(slices.Clone(details.ExtKeyUsage), requested.extKeyUsage...)
+
+	if isCA && !policy.AllowCA {
+		return violationf("CA certificates are not allowed")
+	}
+	if policy.MaxDuration != nil && details.Duration > policy.MaxDuration.Duration {
+		return violationf("duration %s exceeds the maximum of %s", details.Duration, policy.MaxDuration.Duration)
+	}
+
+	if len(policy.AllowedDNSNames) > 0 || len(policy.AllowedURIs) > 0 || len(policy.AllowedEmailAddresses) > 0 || len(policy.AllowedIPRanges) > 0 {
+		// crypto/x509 drops the SANs it can't parse, so they would bypass
+		// the allow-lists.
+		for _, tag := range requested.subjectAltNameTags {
+			if !slices.Contains([]int{sanTagEmail, sanTagDNS, sanTagURI, sanTagIP}, tag) {
+				return violationf("%s SANs are not allowed", sanTagName(tag))
+			}
+		}
+	}
+	if len(policy.AllowedDNSNames) > 0 {
+		if cn := csr.Subject.CommonName; cn != "" && !matchesAny(policy.AllowedDNSNames, cn, '.') {
+			return violationf("common name %q is not allowed", cn)
+		}
+		for _, dnsName := range csr.DNSNames {
+			if !matchesAny(policy.AllowedDNSNames, dnsName, '.') {
+				return violationf("DNS SAN %q is not allowed", dnsName)
+			}
+		}
+	}
+	if len(policy.AllowedURIs) > 0 {
+		for _, uri := range csr.URIs {
+			if !matchesAny(policy.AllowedURIs, uri.String(), '/') {
+				return violationf("URI SAN %q is not allowed", uri)
+			}
+		}
+	}
+	if len(policy.AllowedEmailAddresses) > 0 {
+		for _, email := range csr.EmailAddresses {
+			if !matchesAny(policy.AllowedEmailAddresses, email, '@') {
+				return violationf("email SAN %q is not allowed", email)
+			}
+		}
+	}
+	if len(policy.AllowedIPRanges) > 0 {
+		for _, ip := range csr.IPAddresses {
+			if !inRanges(policy.AllowedIPRanges, ip) {
+				return violationf("IP SAN %q is not allowed", ip)
+			}
+		}
+	}
+
+	if len(policy.AllowedPrivateKeys) > 0 {
+		if err := checkPrivateKey(policy.AllowedPrivateKeys, csr.PublicKey); err != nil {
+			return err
+		}
+	}
+
+	if len(policy.AllowedUsages) > 0 {
+		var allowedKeyUsage x509.KeyUsage
+		var allowedExtKeyUsage []x509.ExtKeyUsage
+		for _, usage := range policy.AllowedUsages {
+			if keyUsage, ok := apiutil.KeyUsageType(usage); ok {
+				allowedKeyUsage |= keyUsage
+			} else if extKeyUsage, ok := apiutil.ExtKeyUsageType(usage); ok {
+				allowedExtKeyUsage = append(allowedExtKeyUsage, extKeyUsage)
+			}
+		}
+		if isCA {
+			// cert-manager adds cert sign to the usages of CA certificates.
+			allowedKeyUsage |= x509.KeyUsageCertSign
+		}
+		if forbidden := keyUsage &^ allowedKeyUsage; forbidden != 0 {
+			return violationf("key usages %q are not allowed", keyUsageNames(forbidden))
+		}
+		for _, usage := range extKeyUsage {
+			if !slices.Contains(allowedExtKeyUsage, usage) {
+				return violationf("key usage %q is not allowed", extKeyUsageName(usage))
+			}
+		}
+		if len(requested.unknownExtKeyUsage) > 0 {
+			return violationf("extended key usage %s is not allowed", requested.unknownExtKeyUsage[0])
+		}
+	}
+
+	return nil
+}
+
+var (
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtKeyUsage      = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+)
+
+// Tags of the GeneralName types of subject alternative names, from RFC 5280.
+const (
+	sanTagOtherName     = 0
+	sanTagEmail         = 1
+	sanTagDNS           = 2
+	sanTagX400Address   = 3
+	sanTagDirectoryName = 4
+	sanTagEDIPartyName  = 5
+	sanTagURI           = 6
+	sanTagIP            = 7
+	sanTagRegisteredID  = 8
+)
+
+var sanTagNames = map[int]string{
+	sanTagOtherName:     "otherName",
+	sanTagEmail:         "email",
+	sanTagDNS:           "DNS",
+	sanTagX400Address:   "x400Address",
+	sanTagDirectoryName: "directoryName",
+	sanTagEDIPartyName:  "ediPartyName",
+	sanTagURI:           "URI",
+	sanTagIP:            "IP",
+	sanTagRegisteredID:  "registeredID",
+}
+
+func sanTagName(tag int) string {
+	if name, ok := sanTagNames[tag]; ok {
+		return name
+	}
+	return fmt.Sprintf("[%d]", tag)
+}
+
+// extKeyUsageOIDs are the extended key usages x509 knows, by OID.
+var extKeyUsageOIDs = map[string]x509.ExtKeyUsage{
+	"2.5.29.37.0":            x509.ExtKeyUsageAny,
+	"1.3.6.1.5.5.7.3.1":      x509.ExtKeyUsageServerAuth,
+	"1.3.6.1.5.5.7.3.2":      x509.ExtKeyUsageClientAuth,
+	"1.3.6.1.5.5.7.3.3":      x509.ExtKeyUsageCodeSigning,
+	"1.3.6.1.5.5.7.3.4":      x509.ExtKeyUsageEmailProtection,
+	"1.3.6.1.5.5.7.3.5":      x509.ExtKeyUsageIPSECEndSystem,
+	"1.3.6.1.5.5.7.3.6":      x509.ExtKeyUsageIPSECTunnel,
+	"1.3.6.1.5.5.7.3.7":      x509.ExtKeyUsageIPSECUser,
+	"1.3.6.1.5.5.7.3.8":      x509.ExtKeyUsageTimeStamping,
+	"1.3.6.1.5.5.7.3.9":      x509.ExtKeyUsageOCSPSigning,
+	"1.3.6.1.4.1.311.10.3.3": x509.ExtKeyUsageMicrosoftServerGatedCrypto,
+	"2.16.840.1.113730.4.1":  x509.ExtKeyUsageNetscapeServerGatedCrypto,
+	"1.3.6.1.4.1.311.2.1.22": x509.ExtKeyUsageMicrosoftCommercialCodeSigning,
+	"1.3.6.1.4.1.311.61.1.1": x509.ExtKeyUsageMicrosoftKernelCodeSigning,
+}
+
+// csrExtensions are the CA flag, usages and types of SANs a CSR asks for in
+// its extensions.
+type csrExtensions struct {
+	isCA               bool
+	keyUsage           x509.KeyUsage
+	extKeyUsage        []x509.ExtKeyUsage
+	unknownExtKeyUsage []asn1.ObjectIdentifier
+	// subjectAltNameTags are the GeneralName tags of the SANs, including
+	// those crypto/x509 doesn't parse.
+	subjectAltNameTags []int
+}
+
+// requestedExtensions parses the basic constraints, key usage, extended key
+// usage and subject alternative name extensions of csr.
+func requestedExtensions(csr *x509.CertificateRequest) (csrExtensions, error) {
+	var requested csrExtensions
+	for _, ext := range csr.Extensions {
+		var err error
+		switch {
+		case ext.Id.Equal(oidExtensionBasicConstraints):
+			var constraints struct {
+				IsCA       bool `asn1:"optional"`
+				MaxPathLen int  `asn1:"optional,default:-1"`
+			}
+			_, err = asn1.Unmarshal(ext.Value, &constraints)
+			requested.isCA = constraints.IsCA
+		case ext.Id.Equal(oidExtensionKeyUsage):
+			var bits asn1.BitString
+			_, err = asn1.Unmarshal(ext.Value, &bits)
+			for i := range 9 {
+				if bits.At(i) != 0 {
+					requested.keyUsage |= 1 << i
+				}
+			}
+		case ext.Id.Equal(oidExtensionExtKeyUsage):
+			var oids []asn1.ObjectIdentifier
+			_, err = asn1.Unmarshal(ext.Value, &oids)
+			for _, oid := range oids {
+				if usage, ok := extKeyUsageOIDs[oid.String()]; ok {
+					requested.extKeyUsage = append(requested.extKeyUsage, usage)
+				} else {
+					requested.unknownExtKeyUsage = append(requested.unknownExtKeyUsage, oid)
+				}
+			}
+		case ext.Id.Equal(oidExtensionSubjectAltName):
+			var names []asn1.RawValue
+			_, err = asn1.Unmarshal(ext.Value, &names)
+			for _, name := range names {
+				if name.Class != asn1.ClassContextSpecific {
+					err = fmt.Errorf("unexpected subject alternative name with class %d", name.Class)
+					break
+				}
+				if !slices.Contains(requested.subjectAltNameTags, name.Tag) {
+					requested.subjectAltNameTags = append(requested.subjectAltNameTags, name.Tag)
+				}
+			}
+		}
+		if err != nil {
+			return csrExtensions{}, fmt.Errorf("failed to parse extension %s of certificate request: %w", ext.Id, err)
+		}
+	}
+	return requested, nil
+}
+
+// checkPrivateKey checks that key uses one of the allowed algorithms, and is
+// at least as large as its minimum size.
+func checkPrivateKey(allowed []issuersv1beta1.PrivateKeyPolicy, key any) error {
+	var algorithm cmapi.PrivateKeyAlgorithm
+	var size int
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		algorithm, size = cmapi.RSAKeyAlgorithm, k.N.BitLen()
+	case *ecdsa.PublicKey:
+		algorithm, size = cmapi.ECDSAKeyAlgorithm, k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		algorithm = cmapi.Ed25519KeyAlgorithm
+	default:
+		return violationf("key type %T is not allowed", key)
+	}
+
+	i := slices.IndexFunc(allowed, func(p issuersv1beta1.PrivateKeyPolicy) bool { return p.Algorithm == algorithm })
+	if i < 0 {
+		return violationf("%s keys are not allowed", algorithm)
+	}
+	if size < allowed[i].MinSize {
+		return violationf("%s key size %d is smaller than the minimum of %d", algorithm, size, allowed[i].MinSize)
+	}
+	return nil
+}
+
+// matchesAny reports whether value matches one of patterns, in which "*"
+// matches one or more characters except sep.
+func matchesAny(patterns []string, value string, sep byte) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return matchPattern(pattern, value, sep)
+	})
+}
+
+func matchPattern(pattern, value string, sep byte) bool {
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, pattern[:star]) {
+		return false
+	}
+	pattern, value = pattern[star+1:], value[star:]
+	// Try every split of value that gives the "*" at least one character and
+	// keeps sep out of them.
+	for i := 0; i < len(value) && value[i] != sep; i++ {
+		if matchPattern(pattern, value[i+1:], sep) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRanges reports whether ip is in one of the CIDR ranges.
+func inRanges(ranges []string, ip []byte) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	return slices.ContainsFunc(ranges, func(cidr string) bool {
+		prefix, err := netip.ParsePrefix(cidr)
+		return err == nil && prefix.Contains(addr)
+	})
+}
+
+// keyUsageNames returns the names of the key usages in usage.
+func keyUsageNames(usage x509.KeyUsage) []cmapi.KeyUsage {
+	names := map[x509.KeyUsage]cmapi.KeyUsage{
+		x509.KeyUsageDigitalSignature:  cmapi.UsageDigitalSignature,
+		x509.KeyUsageContentCommitment: cmapi.UsageContentCommitment,
+		x509.KeyUsageKeyEncipherment:   cmapi.UsageKeyEncipherment,
+		x509.KeyUsageDataEncipherment:  cmapi.UsageDataEncipherment,
+		x509.KeyUsageKeyAgreement:      cmapi.UsageKeyAgreement,
+		x509.KeyUsageCertSign:          cmapi.UsageCertSign,
+		x509.KeyUsageCRLSign:           cmapi.UsageCRLSign,
+		x509.KeyUsageEncipherOnly:      cmapi.UsageEncipherOnly,
+		x509.KeyUsageDecipherOnly:      cmapi.UsageDecipherOnly,
+	}
+	var usages []cmapi.KeyUsage
+	for bit := x509.KeyUsageDigitalSignature; bit <= x509.KeyUsageDecipherOnly; bit <<= 1 {
+		if usage&bit != 0 {
+			usages = append(usages, names[bit])
+		}
+	}
+	return usages
+}
+
+// extKeyUsageName returns the name of an extended key usage.
+func extKeyUsageName(usage x509.ExtKeyUsage) string {
+	names := map[x509.ExtKeyUsage]cmapi.KeyUsage{
+		x509.ExtKeyUsageAny:                        cmapi.UsageAny,
+		x509.ExtKeyUsageServerAuth:                 cmapi.UsageServerAuth,
+		x509.ExtKeyUsageClientAuth:                 cmapi.UsageClientAuth,
+		x509.ExtKeyUsageCodeSigning:                cmapi.UsageCodeSigning,
+		x509.ExtKeyUsageEmailProtection:            cmapi.UsageEmailProtection,
+		x509.ExtKeyUsageIPSECEndSystem:             cmapi.UsageIPsecEndSystem,
+		x509.ExtKeyUsageIPSECTunnel:                cmapi.UsageIPsecTunnel,
+		x509.ExtKeyUsageIPSECUser:                  cmapi.UsageIPsecUser,
+		x509.ExtKeyUsageTimeStamping:               cmapi.UsageTimestamping,
+		x509.ExtKeyUsageOCSPSigning:                cmapi.UsageOCSPSigning,
+		x509.ExtKeyUsageMicrosoftServerGatedCrypto: cmapi.UsageMicrosoftSGC,
+		x509.ExtKeyUsageNetscapeServerGatedCrypto:  cmapi.UsageNetscapeSGC,
+	}
+	if name, ok := names[usage]; ok {
+		return string(name)
+	}
+	return fmt.Sprintf("extended key usage %d", usage)
+}
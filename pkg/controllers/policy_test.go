@@ -0,0 +1,255 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		sep            byte
+		want           bool
+	}{
+		{"example.com", "example.com", '.', true},
+		{"example.com", "www.example.com", '.', false},
+		{"*.example.com", "www.example.com", '.', true},
+		{"*.example.com", "a.b.example.com", '.', false},
+		{"*.example.com", "example.com", '.', false},
+		{"*.example.com", ".example.com", '.', false},
+		{"*@example.com", "@example.com", '@', false},
+		{"api-*.example.com", "api-eu.example.com", '.', true},
+		{"spiffe://example.org/ns/*/sa/*", "spiffe://example.org/ns/default/sa/web", '/', true},
+		{"spiffe://example.org/ns/*/sa/*", "spiffe://example.org/ns/default/sa/web/extra", '/', false},
+		{"*@example.com", "alice@example.com", '@', true},
+		{"*@example.com", "alice@evil.com", '@', false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchPattern(tt.pattern, tt.value, tt.sep), "%q against %q", tt.value, tt.pattern)
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	csr := func(key crypto.Signer, template x509.CertificateRequest) []byte {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/web")
+	require.NoError(t, err)
+	extension := func(id asn1.ObjectIdentifier, value any) pkix.Extension {
+		der, err := asn1.Marshal(value)
+		require.NoError(t, err)
+		return pkix.Extension{Id: id, Value: der}
+	}
+	caExtension := extension(oidExtensionBasicConstraints, struct{ IsCA bool }{true})
+	// Digital signature and key encipherment.
+	keyUsageExtension := extension(oidExtensionKeyUsage, asn1.BitString{Bytes: []byte{0xa0}, BitLength: 3})
+	extKeyUsageExtension := extension(oidExtensionExtKeyUsage, []asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 2}})
+	upnExtension := extension(oidExtensionSubjectAltName, subjectAltNamesWithUPN(t, "www.example.com", "admin@example.com"))
+
+	tests := map[string]struct {
+		policy    *v1beta1.IssuancePolicy
+		details   signer.CertificateDetails
+		wantError string
+	}{
+		"no policy": {
+			details: signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{DNSNames: []string{"anything.org"}}), IsCA: true},
+		},
+		"allowed SANs": {
+			policy: &v1beta1.IssuancePolicy{
+				AllowedDNSNames:       []string{"*.example.com"},
+				AllowedIPRanges:       []string{"10.0.0.0/8"},
+				AllowedURIs:           []string{"spiffe://example.org/ns/*/sa/*"},
+				AllowedEmailAddresses: []string{"*@example.com"},
+			},
+			details: signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{
+				DNSNames:       []string{"www.example.com"},
+				IPAddresses:    []net.IP{net.ParseIP("10.1.2.3")},
+				URIs:           []*url.URL{spiffeID},
+				EmailAddresses: []string{"alice@example.com"},
+			})},
+		},
+		"DNS name not allowed": {
+			policy:    &v1beta1.IssuancePolicy{AllowedDNSNames: []string{"*.example.com"}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{DNSNames: []string{"www.example.com", "www.example.org"}})},
+			wantError: `request violates the issuer's policy: DNS SAN "www.example.org" is not allowed`,
+		},
+		"common name not allowed": {
+			policy:    &v1beta1.IssuancePolicy{AllowedDNSNames: []string{"*.example.com"}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{Subject: pkix.Name{CommonName: "www.example.org"}, DNSNames: []string{"www.example.com"}})},
+			wantError: `request violates the issuer's policy: common name "www.example.org" is not allowed`,
+		},
+		"empty wildcard label": {
+			policy:    &v1beta1.IssuancePolicy{AllowedDNSNames: []string{"*.example.com"}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{DNSNames: []string{".example.com"}})},
+			wantError: `request violates the issuer's policy: DNS SAN ".example.com" is not allowed`,
+		},
+		"otherName SAN not allowed": {
+			policy:    &v1beta1.IssuancePolicy{AllowedDNSNames: []string{"*.example.com"}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{ExtraExtensions: []pkix.Extension{upnExtension}})},
+			wantError: "request violates the issuer's policy: otherName SANs are not allowed",
+		},
+		"otherName SAN without SAN allow-lists": {
+			policy:  &v1beta1.IssuancePolicy{},
+			details: signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{ExtraExtensions: []pkix.Extension{upnExtension}})},
+		},
+		"IP address out of range": {
+			policy:    &v1beta1.IssuancePolicy{AllowedIPRanges: []string{"10.0.0.0/8"}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("192.168.0.1")}})},
+			wantError: `request violates the issuer's policy: IP SAN "192.168.0.1" is not allowed`,
+		},
+		"allowed key": {
+			policy:  &v1beta1.IssuancePolicy{AllowedPrivateKeys: []v1beta1.PrivateKeyPolicy{{Algorithm: cmapi.RSAKeyAlgorithm, MinSize: 2048}}},
+			details: signer.CertificateDetails{CSR: csr(rsaKey, x509.CertificateRequest{DNSNames: []string{"example.com"}})},
+		},
+		"key too small": {
+			policy:    &v1beta1.IssuancePolicy{AllowedPrivateKeys: []v1beta1.PrivateKeyPolicy{{Algorithm: cmapi.ECDSAKeyAlgorithm, MinSize: 384}}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{DNSNames: []string{"example.com"}})},
+			wantError: "request violates the issuer's policy: ECDSA key size 256 is smaller than the minimum of 384",
+		},
+		"key algorithm not allowed": {
+			policy:    &v1beta1.IssuancePolicy{AllowedPrivateKeys: []v1beta1.PrivateKeyPolicy{{Algorithm: cmapi.RSAKeyAlgorithm}}},
+			details:   signer.CertificateDetails{CSR: csr(edKey, x509.CertificateRequest{DNSNames: []string{"example.com"}})},
+			wantError: "request violates the issuer's policy: Ed25519 keys are not allowed",
+		},
+		"duration too long": {
+			policy:    &v1beta1.IssuancePolicy{MaxDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{}), Duration: 48 * time.Hour},
+			wantError: "request violates the issuer's policy: duration 48h0m0s exceeds the maximum of 24h0m0s",
+		},
+		"CA not allowed": {
+			policy:    &v1beta1.IssuancePolicy{},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{}), IsCA: true},
+			wantError: "request violates the issuer's policy: CA certificates are not allowed",
+		},
+		"CA requested by the CSR not allowed": {
+			policy:    &v1beta1.IssuancePolicy{},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{ExtraExtensions: []pkix.Extension{caExtension}})},
+			wantError: "request violates the issuer's policy: CA certificates are not allowed",
+		},
+		"allowed usages": {
+			policy: &v1beta1.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageServerAuth}, AllowCA: true},
+			details: signer.CertificateDetails{
+				CSR:         csr(ecKey, x509.CertificateRequest{}),
+				IsCA:        true,
+				KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			},
+		},
+		"key usage not allowed": {
+			policy: &v1beta1.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature}},
+			details: signer.CertificateDetails{
+				CSR:      csr(ecKey, x509.CertificateRequest{}),
+				KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			},
+			wantError: `request violates the issuer's policy: key usages ["key encipherment"] are not allowed`,
+		},
+		"extended key usage not allowed": {
+			policy: &v1beta1.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageServerAuth}},
+			details: signer.CertificateDetails{
+				CSR:         csr(ecKey, x509.CertificateRequest{}),
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			},
+			wantError: `request violates the issuer's policy: key usage "client auth" is not allowed`,
+		},
+		"key usage requested by the CSR not allowed": {
+			policy:    &v1beta1.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{ExtraExtensions: []pkix.Extension{keyUsageExtension}})},
+			wantError: `request violates the issuer's policy: key usages ["key encipherment"] are not allowed`,
+		},
+		"extended key usage requested by the CSR not allowed": {
+			policy:    &v1beta1.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageServerAuth}},
+			details:   signer.CertificateDetails{CSR: csr(ecKey, x509.CertificateRequest{ExtraExtensions: []pkix.Extension{extKeyUsageExtension}})},
+			wantError: `request violates the issuer's policy: key usage "client auth" is not allowed`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkPolicy(tt.policy, tt.details)
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantError)
+		})
+	}
+}
+
+// subjectAltNamesWithUPN returns the GeneralNames of a subjectAltName
+// extension with a DNS name and a Microsoft UPN otherName, which crypto/x509
+// doesn't parse.
+func subjectAltNamesWithUPN(t *testing.T, dnsName, upn string) []asn1.RawValue {
+	t.Helper()
+
+	typeID, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3})
+	require.NoError(t, err)
+	value, err := asn1.MarshalWithParams(upn, "utf8")
+	require.NoError(t, err)
+	explicitValue, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value})
+	require.NoError(t, err)
+
+	return []asn1.RawValue{
+		{Class: asn1.ClassContextSpecific, Tag: sanTagDNS, Bytes: []byte(dnsName)},
+		{Class: asn1.ClassContextSpecific, Tag: sanTagOtherName, IsCompound: true, Bytes: append(typeID, explicitValue...)},
+	}
+}
+
+func TestSignPolicyViolation(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	spec.Policy = &v1beta1.IssuancePolicy{AllowedDNSNames: []string{"*.example.com"}}
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+
+	_, err := cas.Sign(t.Context(), testCertificateRequest(t, "4d2f6d38-7f43-4f0c-9c39-3f0d0b6f3c21", "www.example.org"), issuer)
+	require.ErrorAs(t, err, &signer.PermanentError{})
+	assert.ErrorContains(t, err, `common name "www.example.org" is not allowed`)
+	assert.Zero(t, fake.Calls("CreateCertificate"), "requests that violate the policy are not sent to CAS")
+
+	_, err = cas.Sign(t.Context(), testCertificateRequest(t, "9b8e1c52-2a6d-4b8f-8e3a-6f1d2c4b5a07", "www.example.com"), issuer)
+	require.NoError(t, err)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	privateca "cloud.google.com/go/security/privateca/apiv1"
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// ConditionTypeCAPoolReady is set on issuers by Check. Its reason tells the
+// failures of the check apart, as issuer-lib sets the reason of the Ready
+// condition itself and uses the same one for every failure.
+const ConditionTypeCAPoolReady = "CAPoolReady"
+
+// ReasonChecked is the reason of a True CAPoolReady condition.
+const ReasonChecked = "Checked"
+
+// Reasons reported when an issuer fails its Check.
+const (
+	ReasonCredentialsUnavailable           = "CredentialsUnavailable"
+	ReasonCAPoolNotFound                   = "CAPoolNotFound"
+	ReasonMissingPermissions               = "MissingPermissions"
+	ReasonNoEnabledCertificateAuthorities  = "NoEnabledCertificateAuthorities"
+	ReasonCertificateAuthorityNotEnabled   = "CertificateAuthorityNotEnabled"
+	ReasonCertificateAuthorityServiceError = "CertificateAuthorityServiceError"
+)
+
+// probeError is returned by Check when the issuer can't be used, with a
+// reason that tells the different failures apart. It is the reason of the
+// issuer's CAPoolReady condition, and prefixes the message of its Ready
+// condition.
+type probeError struct {
+	reason string
+	err    error
+}
+
+func (e probeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.reason, e.err)
+}
+
+func (e probeError) Unwrap() error {
+	return e.err
+}
+
+// transient reports whether the probe failed because CAS couldn't be asked,
+// e.g. it was unavailable or timed out, rather than because of the issuer's
+// configuration or permissions.
+func (e probeError) transient() bool {
+	return e.reason == ReasonCertificateAuthorityServiceError && retryableCode(status.Code(e.err))
+}
+
+// reportProbe sets the CAPoolReady condition of an issuer from the outcome of
+// its Check. Errors that are not a probeError, such as an invalid spec, leave
+// the condition alone. Failing to set it is logged; the next check sets it
+// again.
+func (o *GoogleCAS) reportProbe(ctx context.Context, issuerObj issuerapi.Issuer, err error) {
+	if o.client == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeCAPoolReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonChecked,
+		Message:            "The CA pool passed its checks",
+		ObservedGeneration: issuerObj.GetGeneration(),
+	}
+	if err != nil {
+		var probe probeError
+		if !errors.As(err, &probe) {
+			return
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = probe.reason
+		condition.Message = probe.err.Error()
+	}
+
+	if err := o.setIssuerCondition(ctx, issuerObj, condition); err != nil {
+		ctrl.LoggerFrom(ctx).V(1).Info("Failed to report the CA pool check", "error", err.Error())
+	}
+}
+
+// probeFailure builds a probeError for a failed CAS call, using reason for
+// the codes it is given and ReasonCertificateAuthorityServiceError otherwise.
+func probeFailure(method string, err error, reasons map[codes.Code]string) probeError {
+	st, ok := status.FromError(err)
+	if !ok {
+		return probeError{reason: ReasonCertificateAuthorityServiceError, err: fmt.Errorf("%s failed: %w", method, err)}
+	}
+	reason, ok := reasons[st.Code()]
+	if !ok {
+		reason = ReasonCertificateAuthorityServiceError
+	}
+	return probeError{reason: reason, err: casStatusError{msg: describeCASStatus(method, st), err: err}}
+}
+
+// issuancePermissions returns the IAM permissions the issuer needs on its CA
+// pool to issue certificates.
+func issuancePermissions(issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) []string {
+	permissions := []string{"privateca.certificates.create"}
+	if issuerSpec.SubjectMode == issuersv1beta1.SubjectModeReflectedSPIFFE {
+		permissions = []string{"privateca.certificates.createForSelf"}
+	}
+	if issuerSpec.CAFetchMode == issuersv1beta1.CAFetchModePoolCAs || issuerSpec.TrustBundle != nil {
+		permissions = append(permissions, "privateca.caPools.get")
+	}
+	return permissions
+}
+
+// probeCAPool checks that the issuer's CA pool exists, that the credentials
+// are allowed to issue certificates from it and that it has an enabled CA to
+// issue them with.
+//
+// Reading the pool and listing its CAs needs more than the
+// roles/privateca.certificateRequester role the README recommends, so those
+// checks are skipped when the credentials are not allowed to make them.
+func probeCAPool(ctx context.Context, casClient *privateca.CertificateAuthorityClient, parent string, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) error {
+	log := ctrl.LoggerFrom(ctx).WithValues(logKeyCAPool, parent)
+
+	canReadPool := true
+	if _, err := casClient.GetCaPool(ctx, &casapi.GetCaPoolRequest{Name: parent}); err != nil {
+		if status.Code(err) != codes.PermissionDenied {
+			return probeFailure("GetCaPool", err, map[codes.Code]string{codes.NotFound: ReasonCAPoolNotFound})
+		}
+		log.V(1).Info("Not allowed to read the CA pool, skipping CA checks")
+		canReadPool = false
+	}
+
+	required := issuancePermissions(issuerSpec)
+	resp, err := casClient.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{
+		Resource:    parent,
+		Permissions: required,
+	})
+	if err != nil {
+		return probeFailure("TestIamPermissions", err, map[codes.Code]string{codes.NotFound: ReasonCAPoolNotFound})
+	}
+	if missing := missingPermissions(required, resp.GetPermissions()); len(missing) > 0 {
+		return probeError{
+			reason: ReasonMissingPermissions,
+			err:    fmt.Errorf("the issuer's credentials lack %s on CA pool %s", strings.Join(missing, ", "), parent),
+		}
+	}
+
+	if !canReadPool {
+		return nil
+	}
+
+	enabled, err := enabledCertificateAuthorities(ctx, casClient, parent)
+	if status.Code(err) == codes.PermissionDenied {
+		log.V(1).Info("Not allowed to list certificate authorities, skipping CA checks")
+		return nil
+	}
+	if err != nil {
+		return probeFailure("ListCertificateAuthorities", err, nil)
+	}
+	if len(enabled) == 0 {
+		return probeError{
+			reason: ReasonNoEnabledCertificateAuthorities,
+			err:    fmt.Errorf("CA pool %s has no enabled certificate authorities", parent),
+		}
+	}
+	if issuerSpec.CertificateAuthorityId != "" && !slices.Contains(enabled, issuerSpec.CertificateAuthorityId) {
+		return probeError{
+			reason: ReasonCertificateAuthorityNotEnabled,
+			err:    fmt.Errorf("certificate authority %s in CA pool %s does not exist or is not enabled", issuerSpec.CertificateAuthorityId, parent),
+		}
+	}
+
+	return nil
+}
+
+// enabledCertificateAuthorities returns the IDs of the enabled CAs in a pool.
+func enabledCertificateAuthorities(ctx context.Context, casClient *privateca.CertificateAuthorityClient, parent string) ([]string, error) {
+	var enabled []string
+	it := casClient.ListCertificateAuthorities(ctx, &casapi.ListCertificateAuthoritiesRequest{Parent: parent})
+	for {
+		ca, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return enabled, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ca.GetState() == casapi.CertificateAuthority_ENABLED {
+			enabled = append(enabled, ca.GetName()[strings.LastIndex(ca.GetName(), "/")+1:])
+		}
+	}
+}
+
+// missingPermissions returns the permissions in required that are not granted.
+func missingPermissions(required, granted []string) []string {
+	var missing []string
+	for _, permission := range required {
+		if !slices.Contains(granted, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
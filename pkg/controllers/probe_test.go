@@ -0,0 +1,138 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+	"github.com/cert-manager/google-cas-issuer/pkg/casfake"
+)
+
+func TestIssuancePermissions(t *testing.T) {
+	assert.Equal(t, []string{"privateca.certificates.create"},
+		issuancePermissions(&issuersv1beta1.GoogleCASIssuerSpec{}))
+	assert.Equal(t, []string{"privateca.certificates.create", "privateca.caPools.get"},
+		issuancePermissions(&issuersv1beta1.GoogleCASIssuerSpec{CAFetchMode: issuersv1beta1.CAFetchModePoolCAs}))
+	assert.Equal(t, []string{"privateca.certificates.create", "privateca.caPools.get"},
+		issuancePermissions(&issuersv1beta1.GoogleCASIssuerSpec{TrustBundle: &issuersv1beta1.TrustBundle{ConfigMapName: "roots"}}))
+	assert.Equal(t, []string{"privateca.certificates.createForSelf"},
+		issuancePermissions(&issuersv1beta1.GoogleCASIssuerSpec{SubjectMode: issuersv1beta1.SubjectModeReflectedSPIFFE}))
+}
+
+func TestMissingPermissions(t *testing.T) {
+	required := []string{"privateca.certificates.create", "privateca.caPools.get"}
+
+	assert.Empty(t, missingPermissions(required, required))
+	assert.Equal(t, []string{"privateca.caPools.get"}, missingPermissions(required, []string{"privateca.certificates.create"}))
+	assert.Equal(t, required, missingPermissions(required, nil))
+}
+
+func TestProbeFailure(t *testing.T) {
+	reasons := map[codes.Code]string{codes.NotFound: ReasonCAPoolNotFound}
+
+	err := probeFailure("GetCaPool", status.Error(codes.NotFound, "Resource not found."), reasons)
+	assert.EqualError(t, err, "CAPoolNotFound: Certificate Authority Service GetCaPool failed (NotFound): Resource not found.")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	err = probeFailure("GetCaPool", status.Error(codes.Unavailable, "try again"), reasons)
+	assert.Equal(t, ReasonCertificateAuthorityServiceError, err.reason)
+
+	err = probeFailure("GetCaPool", context.DeadlineExceeded, reasons)
+	assert.EqualError(t, err, "CertificateAuthorityServiceError: GetCaPool failed: context deadline exceeded")
+
+	// Probe failures are retried, so the issuer recovers once the pool is fixed.
+	assert.False(t, errors.As(err, &signer.PermanentError{}))
+}
+
+func TestCheck(t *testing.T) {
+	const pool = "projects/my-project/locations/us-east1/caPools/my-pool"
+
+	tests := map[string]struct {
+		setup      func(fake *casfake.Server, spec *issuersv1beta1.GoogleCASIssuerSpec)
+		wantReason string
+	}{
+		"ready": {
+			setup: func(*casfake.Server, *issuersv1beta1.GoogleCASIssuerSpec) {},
+		},
+		"pool not found": {
+			setup: func(_ *casfake.Server, spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.CaPoolId = "other-pool"
+			},
+			wantReason: ReasonCAPoolNotFound,
+		},
+		"missing permissions": {
+			setup: func(fake *casfake.Server, _ *issuersv1beta1.GoogleCASIssuerSpec) {
+				fake.SetPermissions(pool, "privateca.caPools.get")
+			},
+			wantReason: ReasonMissingPermissions,
+		},
+		"no enabled certificate authorities": {
+			setup: func(fake *casfake.Server, _ *issuersv1beta1.GoogleCASIssuerSpec) {
+				fake.SetCertificateAuthorityState(pool+"/certificateAuthorities/root-1", casapi.CertificateAuthority_DISABLED)
+			},
+			wantReason: ReasonNoEnabledCertificateAuthorities,
+		},
+		"certificate authority not enabled": {
+			setup: func(fake *casfake.Server, spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				_, err := fake.AddCertificateAuthority(pool, "root-2", casapi.CertificateAuthority_STAGED)
+				require.NoError(t, err)
+				spec.CertificateAuthorityId = "root-2"
+			},
+			wantReason: ReasonCertificateAuthorityNotEnabled,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake, cas, spec := newFakeCAS(t)
+			tt.setup(fake, spec)
+
+			issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+			require.NoError(t, cas.client.Create(t.Context(), issuer))
+
+			err := cas.Check(t.Context(), issuer)
+			require.NoError(t, cas.client.Get(t.Context(), client.ObjectKeyFromObject(issuer), issuer))
+			condition := apimeta.FindStatusCondition(issuer.Status.Conditions, ConditionTypeCAPoolReady)
+			require.NotNil(t, condition)
+			assert.Equal(t, issuer.Generation, condition.ObservedGeneration)
+			if tt.wantReason == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				assert.Equal(t, ReasonChecked, condition.Reason)
+				return
+			}
+			var probeErr probeError
+			require.ErrorAs(t, err, &probeErr)
+			assert.Equal(t, tt.wantReason, probeErr.reason)
+			assert.Equal(t, metav1.ConditionFalse, condition.Status)
+			assert.Equal(t, tt.wantReason, condition.Reason, "the condition's reason tells the failures apart")
+		})
+	}
+}
@@ -0,0 +1,346 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// revocationFinalizer keeps a CertificateRequest around until the CAS
+// certificate issued for it has been revoked, if its issuer's revocation
+// policy asks for that.
+const revocationFinalizer = "cas-issuer.jetstack.io/revocation"
+
+// defaultRevocationGracePeriod is how long superseded certificates stay valid
+// if the revocation policy doesn't set a grace period.
+const defaultRevocationGracePeriod = time.Hour
+
+var revocationReasons = map[issuersv1beta1.RevocationReason]casapi.RevocationReason{
+	issuersv1beta1.RevocationReasonUnspecified:                    casapi.RevocationReason_REVOCATION_REASON_UNSPECIFIED,
+	issuersv1beta1.RevocationReasonKeyCompromise:                  casapi.RevocationReason_KEY_COMPROMISE,
+	issuersv1beta1.RevocationReasonCertificateAuthorityCompromise: casapi.RevocationReason_CERTIFICATE_AUTHORITY_COMPROMISE,
+	issuersv1beta1.RevocationReasonAffiliationChanged:             casapi.RevocationReason_AFFILIATION_CHANGED,
+	issuersv1beta1.RevocationReasonSuperseded:                     casapi.RevocationReason_SUPERSEDED,
+	issuersv1beta1.RevocationReasonCessationOfOperation:           casapi.RevocationReason_CESSATION_OF_OPERATION,
+	issuersv1beta1.RevocationReasonCertificateHold:                casapi.RevocationReason_CERTIFICATE_HOLD,
+	issuersv1beta1.RevocationReasonPrivilegeWithdrawn:             casapi.RevocationReason_PRIVILEGE_WITHDRAWN,
+	issuersv1beta1.RevocationReasonAttributeAuthorityCompromise:   casapi.RevocationReason_ATTRIBUTE_AUTHORITY_COMPROMISE,
+}
+
+func revocationReason(policy *issuersv1beta1.RevocationPolicy) casapi.RevocationReason {
+	if reason, ok := revocationReasons[policy.Reason]; ok {
+		return reason
+	}
+	return casapi.RevocationReason_CESSATION_OF_OPERATION
+}
+
+// revocationController revokes the CAS certificates of retired
+// CertificateRequests, according to the revocation policy of their issuer.
+type revocationController struct {
+	cas      *GoogleCAS
+	client   client.Client
+	recorder events.EventRecorder
+	now      func() time.Time
+}
+
+// certificateOwnerField indexes CertificateRequests by the UID of the
+// Certificate that controls them.
+const certificateOwnerField = "metadata.controller.certificate"
+
+func certificateOwnerUID(obj client.Object) []string {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.APIVersion != cmapi.SchemeGroupVersion.String() || owner.Kind != cmapi.CertificateKind {
+		return nil
+	}
+	return []string{string(owner.UID)}
+}
+
+func (r *revocationController) SetupWithManager(ctx context.Context, mgr ctrl.Manager, ctrlOpts controller.Options) error {
+	r.client = mgr.GetClient()
+	r.recorder = mgr.GetEventRecorder("cas-issuer.jetstack.io")
+	r.now = time.Now
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &cmapi.CertificateRequest{}, certificateOwnerField, certificateOwnerUID); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("revocation").
+		For(&cmapi.CertificateRequest{}, builder.WithPredicates(predicate.NewPredicateFuncs(hasCertificateName))).
+		Watches(&cmapi.Certificate{}, handler.EnqueueRequestsFromMapFunc(r.requestsForCertificate)).
+		WithOptions(ctrlOpts).
+		Complete(r)
+}
+
+func hasCertificateName(obj client.Object) bool {
+	_, ok := obj.GetAnnotations()[CertificateNameAnnotation]
+	return ok
+}
+
+// requestsForCertificate maps a Certificate to the CertificateRequests it
+// owns, so that they are revisited when it is renewed or deleted.
+func (r *revocationController) requestsForCertificate(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list cmapi.CertificateRequestList
+	if err := r.client.List(ctx, &list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{certificateOwnerField: string(obj.GetUID())}); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list CertificateRequests")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range list.Items {
+		cr := &list.Items[i]
+		if hasCertificateName(cr) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		}
+	}
+	return requests
+}
+
+// Reconcile revokes the CAS certificate of a CertificateRequest once one of
+// the events in its issuer's revocation policy happened, and keeps the
+// revocation finalizer on the request until then.
+func (r *revocationController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var cr cmapi.CertificateRequest
+	if err := r.client.Get(ctx, req.NamespacedName, &cr); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	certificateName := cr.Annotations[CertificateNameAnnotation]
+	if certificateName == "" || cr.Annotations[RevokedAnnotation] != "" {
+		return ctrl.Result{}, r.removeFinalizer(ctx, &cr)
+	}
+
+	issuerObj, err := r.issuerFor(ctx, &cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if issuerObj == nil {
+		log.V(1).Info("Issuer not found, the certificate can't be revoked", logKeyCASCertificate, certificateName)
+		return ctrl.Result{}, r.removeFinalizer(ctx, &cr)
+	}
+	ctx = withIssuerLogger(ctx, issuerObj, logKeyCASCertificate, certificateName)
+	log = ctrl.LoggerFrom(ctx)
+	issuerSpec, _ := r.cas.extractIssuerSpec(issuerObj)
+	policy := issuerSpec.Revocation
+	if policy == nil {
+		return ctrl.Result{}, r.removeFinalizer(ctx, &cr)
+	}
+	if !slices.Contains(issuedCertificateNames(issuerSpec, &cr), certificateName) {
+		// Anyone who can edit the CertificateRequest can set the annotation,
+		// so only certificates the issuer could have issued for it are
+		// revoked with the issuer's credentials.
+		log.Info("Not revoking a CAS certificate that wasn't issued for the CertificateRequest")
+		r.recorder.Eventf(&cr, nil, corev1.EventTypeWarning, "RevocationRefused", "Revoke", "Refusing to revoke CAS certificate %s, which the issuer didn't issue for this CertificateRequest", certificateName)
+		return ctrl.Result{}, r.removeFinalizer(ctx, &cr)
+	}
+
+	if cr.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(&cr, revocationFinalizer) {
+		patch := client.MergeFromWithOptions(cr.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.AddFinalizer(&cr, revocationFinalizer)
+		if err := r.client.Patch(ctx, &cr, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	trigger, requeueAfter, err := r.revocationTrigger(ctx, &cr, policy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if trigger == "" {
+		if requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		if !cr.DeletionTimestamp.IsZero() {
+			return ctrl.Result{}, r.removeFinalizer(ctx, &cr)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.revoke(ctx, issuerObj, certificateName, revocationReason(policy)); err != nil {
+		r.recorder.Eventf(&cr, nil, corev1.EventTypeWarning, "RevocationFailed", "Revoke", "Failed to revoke CAS certificate %s: %v", certificateName, err)
+		return ctrl.Result{}, err
+	}
+	log.Info("Revoked CAS certificate", "trigger", trigger)
+	r.recorder.Eventf(&cr, nil, corev1.EventTypeNormal, "Revoked", "Revoke", "Revoked CAS certificate %s: %s", certificateName, trigger)
+
+	patch := client.MergeFromWithOptions(cr.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	metav1.SetMetaDataAnnotation(&cr.ObjectMeta, RevokedAnnotation, r.now().UTC().Format(time.RFC3339))
+	controllerutil.RemoveFinalizer(&cr, revocationFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.client.Patch(ctx, &cr, patch))
+}
+
+// issuerFor returns the issuer of cr, or nil if it isn't one of ours or it
+// doesn't exist anymore.
+func (r *revocationController) issuerFor(ctx context.Context, cr *cmapi.CertificateRequest) (issuerapi.Issuer, error) {
+	ref := cr.Spec.IssuerRef
+	if ref.Group != issuersv1beta1.GroupVersion.Group {
+		return nil, nil
+	}
+
+	var issuerObj issuerapi.Issuer
+	key := types.NamespacedName{Name: ref.Name}
+	if ref.Kind == "GoogleCASClusterIssuer" {
+		issuerObj = &issuersv1beta1.GoogleCASClusterIssuer{}
+	} else {
+		issuerObj = &issuersv1beta1.GoogleCASIssuer{}
+		key.Namespace = cr.Namespace
+	}
+	if err := r.client.Get(ctx, key, issuerObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return issuerObj, nil
+}
+
+// issuedCertificateNames returns the names the CAS certificate of cr can
+// have: the certificate ID the signer derives from cr, in each of the
+// issuer's CA pools.
+func issuedCertificateNames(issuerSpec *issuersv1beta1.GoogleCASIssuerSpec, cr *cmapi.CertificateRequest) []string {
+	certificateID, _ := certificateIDs(cr)
+	var names []string
+	for _, target := range caPoolTargets(issuerSpec) {
+		parent, err := buildParentString(target)
+		if err != nil {
+			continue
+		}
+		names = append(names, parent+"/certificates/"+certificateID)
+	}
+	return names
+}
+
+// revocationTrigger returns the event of policy that revokes the certificate
+// of cr, if any. If cr has been superseded but is still within its grace
+// period, it returns how long until the grace period ends.
+func (r *revocationController) revocationTrigger(ctx context.Context, cr *cmapi.CertificateRequest, policy *issuersv1beta1.RevocationPolicy) (issuersv1beta1.RevocationTrigger, time.Duration, error) {
+	if !cr.DeletionTimestamp.IsZero() && slices.Contains(policy.On, issuersv1beta1.RevokeOnCertificateRequestDeleted) {
+		return issuersv1beta1.RevokeOnCertificateRequestDeleted, 0, nil
+	}
+
+	owner := metav1.GetControllerOf(cr)
+	if owner == nil || owner.Kind != cmapi.CertificateKind {
+		return "", 0, nil
+	}
+	var certificate cmapi.Certificate
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: cr.Namespace, Name: owner.Name}, &certificate)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", 0, err
+	}
+	deleted := err != nil || certificate.UID != owner.UID || !certificate.DeletionTimestamp.IsZero()
+
+	if deleted {
+		if slices.Contains(policy.On, issuersv1beta1.RevokeOnCertificateDeleted) {
+			return issuersv1beta1.RevokeOnCertificateDeleted, 0, nil
+		}
+		return "", 0, nil
+	}
+
+	if !slices.Contains(policy.On, issuersv1beta1.RevokeOnSuperseded) {
+		return "", 0, nil
+	}
+	supersededAt, ok := supersededAt(cr, &certificate)
+	if !ok {
+		return "", 0, nil
+	}
+	gracePeriod := defaultRevocationGracePeriod
+	if policy.GracePeriod != nil {
+		gracePeriod = policy.GracePeriod.Duration
+	}
+	if wait := supersededAt.Add(gracePeriod).Sub(r.now()); wait > 0 {
+		return "", wait, nil
+	}
+	return issuersv1beta1.RevokeOnSuperseded, 0, nil
+}
+
+// supersededAt returns when certificate was renewed with a newer revision
+// than the one cr was created for, if it was.
+func supersededAt(cr *cmapi.CertificateRequest, certificate *cmapi.Certificate) (time.Time, bool) {
+	revision, err := strconv.Atoi(cr.Annotations[cmapi.CertificateRequestRevisionAnnotationKey])
+	if err != nil || certificate.Status.Revision == nil || *certificate.Status.Revision <= revision {
+		return time.Time{}, false
+	}
+	if certificate.Status.NotBefore == nil {
+		return time.Time{}, false
+	}
+	return certificate.Status.NotBefore.Time, true
+}
+
+// revoke revokes a CAS certificate with the credentials of issuerObj. A
+// certificate that was already revoked or doesn't exist anymore counts as
+// revoked.
+func (r *revocationController) revoke(ctx context.Context, issuerObj issuerapi.Issuer, name string, reason casapi.RevocationReason) error {
+	issuerSpec, resourceNamespace := r.cas.extractIssuerSpec(issuerObj)
+
+	casClient, release, err := r.cas.createCasClient(ctx, resourceNamespace, issuerSpec)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	_, err = casClient.RevokeCertificate(ctx, &casapi.RevokeCertificateRequest{
+		Name:      name,
+		Reason:    reason,
+		RequestId: uuid.NewSHA1(requestIDNamespace, []byte("revoke/"+name)).String(),
+	})
+	switch status.Code(err) {
+	case codes.OK, codes.NotFound:
+		return nil
+	case codes.FailedPrecondition:
+		certificate, getErr := casClient.GetCertificate(ctx, &casapi.GetCertificateRequest{Name: name})
+		if getErr == nil && certificate.GetRevocationDetails() != nil {
+			return nil
+		}
+	}
+	return casError("RevokeCertificate", err)
+}
+
+func (r *revocationController) removeFinalizer(ctx context.Context, cr *cmapi.CertificateRequest) error {
+	if !controllerutil.ContainsFinalizer(cr, revocationFinalizer) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(cr.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(cr, revocationFinalizer)
+	return client.IgnoreNotFound(r.client.Patch(ctx, cr, patch))
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestRevocationReason(t *testing.T) {
+	assert.Equal(t, casapi.RevocationReason_CESSATION_OF_OPERATION, revocationReason(&issuersv1beta1.RevocationPolicy{}))
+	assert.Equal(t, casapi.RevocationReason_KEY_COMPROMISE, revocationReason(&issuersv1beta1.RevocationPolicy{Reason: issuersv1beta1.RevocationReasonKeyCompromise}))
+}
+
+func TestSupersededAt(t *testing.T) {
+	renewed := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	cr := &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "1"}}}
+
+	at, ok := supersededAt(cr, &cmapi.Certificate{Status: cmapi.CertificateStatus{Revision: ptr.To(2), NotBefore: &renewed}})
+	assert.True(t, ok)
+	assert.Equal(t, renewed.Time, at)
+
+	_, ok = supersededAt(cr, &cmapi.Certificate{Status: cmapi.CertificateStatus{Revision: ptr.To(1), NotBefore: &renewed}})
+	assert.False(t, ok, "the current revision isn't superseded")
+
+	_, ok = supersededAt(&cmapi.CertificateRequest{}, &cmapi.Certificate{Status: cmapi.CertificateStatus{Revision: ptr.To(2), NotBefore: &renewed}})
+	assert.False(t, ok, "requests without a revision can't be superseded")
+}
+
+func TestRevocationController(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := map[string]struct {
+		policy issuersv1beta1.RevocationPolicy
+		// retire retires the CertificateRequest, whose owner Certificate is
+		// at revision 1.
+		retire func(t *testing.T, kubeClient client.Client, certificate *cmapi.Certificate, cr *cmapi.CertificateRequest)
+
+		wantRevoked      bool
+		wantRequeueAfter time.Duration
+	}{
+		"certificate request deleted": {
+			policy: issuersv1beta1.RevocationPolicy{On: []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnCertificateRequestDeleted}},
+			retire: func(t *testing.T, kubeClient client.Client, _ *cmapi.Certificate, cr *cmapi.CertificateRequest) {
+				require.NoError(t, kubeClient.Delete(t.Context(), cr))
+			},
+			wantRevoked: true,
+		},
+		"certificate request deleted without a matching policy": {
+			policy: issuersv1beta1.RevocationPolicy{On: []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnCertificateDeleted}},
+			retire: func(t *testing.T, kubeClient client.Client, _ *cmapi.Certificate, cr *cmapi.CertificateRequest) {
+				require.NoError(t, kubeClient.Delete(t.Context(), cr))
+			},
+		},
+		"certificate deleted": {
+			policy: issuersv1beta1.RevocationPolicy{On: []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnCertificateDeleted}},
+			retire: func(t *testing.T, kubeClient client.Client, certificate *cmapi.Certificate, cr *cmapi.CertificateRequest) {
+				require.NoError(t, kubeClient.Delete(t.Context(), certificate))
+				require.NoError(t, kubeClient.Delete(t.Context(), cr))
+			},
+			wantRevoked: true,
+		},
+		"superseded after the grace period": {
+			policy: issuersv1beta1.RevocationPolicy{
+				On:          []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnSuperseded},
+				GracePeriod: &metav1.Duration{Duration: time.Hour},
+			},
+			retire: func(t *testing.T, kubeClient client.Client, certificate *cmapi.Certificate, _ *cmapi.CertificateRequest) {
+				certificate.Status.Revision = ptr.To(2)
+				certificate.Status.NotBefore = ptr.To(metav1.NewTime(now.Add(-2 * time.Hour)))
+				require.NoError(t, kubeClient.Update(t.Context(), certificate))
+			},
+			wantRevoked: true,
+		},
+		"superseded within the grace period": {
+			policy: issuersv1beta1.RevocationPolicy{
+				On:          []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnSuperseded},
+				GracePeriod: &metav1.Duration{Duration: time.Hour},
+			},
+			retire: func(t *testing.T, kubeClient client.Client, certificate *cmapi.Certificate, _ *cmapi.CertificateRequest) {
+				certificate.Status.Revision = ptr.To(2)
+				certificate.Status.NotBefore = ptr.To(metav1.NewTime(now.Add(-15 * time.Minute)))
+				require.NoError(t, kubeClient.Update(t.Context(), certificate))
+			},
+			wantRequeueAfter: 45 * time.Minute,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			casServer, cas, spec := newFakeCAS(t)
+			spec.Revocation = &tt.policy
+			kubeClient := cas.client
+
+			issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"}, Spec: *spec}
+			certificate := &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert", Namespace: "default", UID: "cert-uid"},
+				Status:     cmapi.CertificateStatus{Revision: ptr.To(1)},
+			}
+			cr := testCertificateRequestFor(t, "default", "cert-1", cmmetav1.IssuerReference{
+				Group: issuersv1beta1.GroupVersion.Group,
+				Kind:  "GoogleCASIssuer",
+				Name:  "issuer",
+			})
+			cr.UID = "cr-uid"
+			cr.Annotations = map[string]string{cmapi.CertificateRequestRevisionAnnotationKey: "1"}
+			cr.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: cmapi.SchemeGroupVersion.String(),
+				Kind:       cmapi.CertificateKind,
+				Name:       "cert",
+				UID:        "cert-uid",
+				Controller: ptr.To(true),
+			}}
+			for _, obj := range []client.Object{issuer, certificate, cr} {
+				require.NoError(t, kubeClient.Create(t.Context(), obj))
+			}
+
+			_, err := cas.Sign(t.Context(), signer.CertificateRequestObjectFromCertificateRequest(cr), issuer)
+			require.NoError(t, err)
+			require.NoError(t, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(cr), cr))
+			certificateName := cr.Annotations[CertificateNameAnnotation]
+			assert.Equal(t, casServer.Certificates()[0].GetName(), certificateName)
+			assert.True(t, controllerutil.ContainsFinalizer(cr, revocationFinalizer))
+
+			tt.retire(t, kubeClient, certificate, cr)
+
+			r := &revocationController{cas: cas, client: kubeClient, recorder: &events.FakeRecorder{}, now: func() time.Time { return now }}
+			result, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRequeueAfter, result.RequeueAfter)
+
+			revoked := casServer.Certificates()[0].GetRevocationDetails()
+			if !tt.wantRevoked {
+				assert.Nil(t, revoked)
+				return
+			}
+			require.NotNil(t, revoked)
+			assert.Equal(t, casapi.RevocationReason_CESSATION_OF_OPERATION, revoked.GetRevocationState())
+
+			err = kubeClient.Get(t.Context(), client.ObjectKeyFromObject(cr), cr)
+			if apierrors.IsNotFound(err) {
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, cr.Annotations[RevokedAnnotation])
+			assert.False(t, controllerutil.ContainsFinalizer(cr, revocationFinalizer))
+		})
+	}
+}
+
+func TestRevocationControllerTamperedCertificateName(t *testing.T) {
+	casServer, cas, spec := newFakeCAS(t)
+	spec.Revocation = &issuersv1beta1.RevocationPolicy{On: []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnCertificateRequestDeleted}}
+	kubeClient := cas.client
+
+	issuer := &issuersv1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer"}, Spec: *spec}
+	require.NoError(t, kubeClient.Create(t.Context(), issuer))
+	issuerRef := cmmetav1.IssuerReference{Group: issuersv1beta1.GroupVersion.Group, Kind: "GoogleCASClusterIssuer", Name: "issuer"}
+
+	var crs []*cmapi.CertificateRequest
+	for _, namespace := range []string{"victim", "tenant"} {
+		cr := testCertificateRequestFor(t, namespace, "cert-1", issuerRef)
+		cr.UID = types.UID(namespace + "-uid")
+		require.NoError(t, kubeClient.Create(t.Context(), cr))
+		_, err := cas.Sign(t.Context(), signer.CertificateRequestObjectFromCertificateRequest(cr), issuer)
+		require.NoError(t, err)
+		require.NoError(t, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(cr), cr))
+		crs = append(crs, cr)
+	}
+	victim, tenant := crs[0], crs[1]
+
+	// The tenant points its own request at the victim's certificate, and
+	// deletes it to trigger the revocation.
+	victimCertificate := victim.Annotations[CertificateNameAnnotation]
+	tenant.Annotations[CertificateNameAnnotation] = victimCertificate
+	require.NoError(t, kubeClient.Update(t.Context(), tenant))
+	require.NoError(t, kubeClient.Delete(t.Context(), tenant))
+
+	recorder := &events.FakeRecorder{Events: make(chan string, 10)}
+	r := &revocationController{cas: cas, client: kubeClient, recorder: recorder, now: time.Now}
+	_, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(tenant)})
+	require.NoError(t, err)
+	assert.Contains(t, <-recorder.Events, "RevocationRefused")
+
+	for _, certificate := range casServer.Certificates() {
+		assert.Nil(t, certificate.GetRevocationDetails(), certificate.GetName())
+	}
+	assert.Zero(t, casServer.Calls("RevokeCertificate"))
+	err = kubeClient.Get(t.Context(), client.ObjectKeyFromObject(tenant), tenant)
+	assert.True(t, apierrors.IsNotFound(err), "the finalizer is removed")
+}
+
+func TestRequestsForCertificate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, cmapi.AddToScheme(scheme))
+
+	request := func(namespace, name, ownerUID string, recorded bool) *cmapi.CertificateRequest {
+		cr := &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		if ownerUID != "" {
+			cr.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: cmapi.SchemeGroupVersion.String(),
+				Kind:       cmapi.CertificateKind,
+				Name:       "cert",
+				UID:        types.UID(ownerUID),
+				Controller: ptr.To(true),
+			}}
+		}
+		if recorded {
+			cr.Annotations = map[string]string{CertificateNameAnnotation: "projects/p/locations/l/caPools/c/certificates/" + name}
+		}
+		return cr
+	}
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&cmapi.CertificateRequest{}, certificateOwnerField, certificateOwnerUID).
+		WithObjects(
+			request("default", "cert-1", "cert-uid", true),
+			request("default", "cert-2", "cert-uid", true),
+			request("default", "cert-unrecorded", "cert-uid", false),
+			request("default", "other-1", "other-uid", true),
+			request("default", "unowned", "", true),
+		).
+		Build()
+	r := &revocationController{client: kubeClient}
+
+	certificate := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Name: "cert", Namespace: "default", UID: "cert-uid"}}
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "cert-1"}},
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "cert-2"}},
+	}, r.requestsForCertificate(t.Context(), certificate))
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// credentialsSecretField indexes issuers by the name of the Secret referenced
+// by spec.credentials.
+const credentialsSecretField = "spec.credentials.name"
+
+func credentialsSecretName(obj client.Object) []string {
+	var name string
+	switch t := obj.(type) {
+	case *issuersv1beta1.GoogleCASIssuer:
+		name = t.Spec.Credentials.Name
+	case *issuersv1beta1.GoogleCASClusterIssuer:
+		name = t.Spec.Credentials.Name
+	}
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+// watchCredentialsSecrets makes the issuer controller for gvk re-check every
+// issuer that references a Secret when that Secret changes, see
+// recheckIssuers. It is called for every controller set up by issuer-lib and
+// ignores those that are not for one of our issuer types.
+func watchCredentialsSecrets(ctx context.Context, gvk schema.GroupVersionKind, mgr ctrl.Manager, b *builder.Builder) error {
+	var (
+		issuer     issuerapi.Issuer
+		list       client.ObjectList
+		namespaced bool
+	)
+	switch gvk {
+	case issuersv1beta1.GroupVersion.WithKind("GoogleCASIssuer"):
+		issuer, list, namespaced = &issuersv1beta1.GoogleCASIssuer{}, &issuersv1beta1.GoogleCASIssuerList{}, true
+	case issuersv1beta1.GroupVersion.WithKind("GoogleCASClusterIssuer"):
+		issuer, list = &issuersv1beta1.GoogleCASClusterIssuer{}, &issuersv1beta1.GoogleCASClusterIssuerList{}
+	default:
+		return nil
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, issuer, credentialsSecretField, credentialsSecretName); err != nil {
+		return err
+	}
+
+	b.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(
+		recheckIssuers(mgr.GetClient(), issuer, issuersForSecret(mgr.GetClient(), list, namespaced)),
+	))
+
+	return nil
+}
+
+// issuersForSecret returns a map func that lists the issuers of list's type
+// that reference a Secret. Cluster issuers only reference Secrets in the
+// cluster resource namespace.
+func issuersForSecret(kubeClient client.Client, list client.ObjectList, namespaced bool) handler.MapFunc {
+	return func(ctx context.Context, secret client.Object) []reconcile.Request {
+		opts := []client.ListOption{client.MatchingFields{credentialsSecretField: secret.GetName()}}
+		if namespaced {
+			opts = append(opts, client.InNamespace(secret.GetNamespace()))
+		} else if secret.GetNamespace() != viper.GetString("cluster-resource-namespace") {
+			return nil
+		}
+
+		issuers := list.DeepCopyObject().(client.ObjectList)
+		if err := kubeClient.List(ctx, issuers, opts...); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list issuers referencing Secret", "secret", client.ObjectKeyFromObject(secret))
+			return nil
+		}
+
+		var requests []reconcile.Request
+		_ = meta.EachListItem(issuers, func(obj runtime.Object) error {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj.(client.Object))})
+			return nil
+		})
+		return requests
+	}
+}
+
+// recheckIssuers wraps a map func that returns issuers of issuer's type, and
+// resets the observed generation of their Ready condition. issuer-lib doesn't
+// check an issuer again while it is Ready for its current generation, so
+// reconciling it alone wouldn't notice that its credentials changed.
+func recheckIssuers(kubeClient client.Client, issuer issuerapi.Issuer, issuers handler.MapFunc) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		requests := issuers(ctx, obj)
+		for _, req := range requests {
+			if err := resetReadyGeneration(ctx, kubeClient, issuer, req.NamespacedName); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "failed to reset the Ready condition of issuer", "issuer", req.NamespacedName)
+			}
+		}
+		return requests
+	}
+}
+
+// resetReadyGeneration sets the observed generation of the Ready condition of
+// an issuer to zero, unless the condition is out of date already.
+func resetReadyGeneration(ctx context.Context, kubeClient client.Client, issuer issuerapi.Issuer, key types.NamespacedName) error {
+	latest := issuer.DeepCopyObject().(issuerapi.Issuer)
+	if err := kubeClient.Get(ctx, key, latest); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	status := issuerStatus(latest)
+	if status == nil {
+		return nil
+	}
+	ready := meta.FindStatusCondition(status.Conditions, issuerapi.IssuerConditionTypeReady)
+	if ready == nil || ready.ObservedGeneration != latest.GetGeneration() {
+		return nil
+	}
+
+	patch := client.MergeFromWithOptions(latest.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	ready.ObservedGeneration = 0
+	return kubeClient.Status().Patch(ctx, latest, patch)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestIssuersForSecret(t *testing.T) {
+	previous := viper.GetString("cluster-resource-namespace")
+	viper.Set("cluster-resource-namespace", "cert-manager")
+	t.Cleanup(func() { viper.Set("cluster-resource-namespace", previous) })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuersv1beta1.AddToScheme(scheme))
+
+	credentials := func(name string) issuersv1beta1.GoogleCASIssuerSpec {
+		return issuersv1beta1.GoogleCASIssuerSpec{
+			Credentials: cmmetav1.SecretKeySelector{LocalObjectReference: cmmetav1.LocalObjectReference{Name: name}, Key: "key.json"},
+		}
+	}
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&issuersv1beta1.GoogleCASIssuer{}, credentialsSecretField, credentialsSecretName).
+		WithIndex(&issuersv1beta1.GoogleCASClusterIssuer{}, credentialsSecretField, credentialsSecretName).
+		WithObjects(
+			&issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "uses-sa", Namespace: "tenant-a"}, Spec: credentials("sa")},
+			&issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "uses-other", Namespace: "tenant-a"}, Spec: credentials("other")},
+			&issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "uses-sa", Namespace: "tenant-b"}, Spec: credentials("sa")},
+			&issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "ambient", Namespace: "tenant-a"}},
+			&issuersv1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "cluster-uses-sa"}, Spec: credentials("sa")},
+		).
+		Build()
+
+	issuers := issuersForSecret(kubeClient, &issuersv1beta1.GoogleCASIssuerList{}, true)
+	clusterIssuers := issuersForSecret(kubeClient, &issuersv1beta1.GoogleCASClusterIssuerList{}, false)
+
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	assert.Equal(t,
+		[]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "tenant-a", Name: "uses-sa"}}},
+		issuers(t.Context(), secret("tenant-a", "sa")))
+	assert.Empty(t, issuers(t.Context(), secret("tenant-a", "unused")))
+	assert.Empty(t, clusterIssuers(t.Context(), secret("tenant-a", "sa")),
+		"cluster issuers only reference Secrets in the cluster resource namespace")
+	assert.Equal(t,
+		[]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "cluster-uses-sa"}}},
+		clusterIssuers(t.Context(), secret("cert-manager", "sa")))
+}
+
+func TestRecheckIssuers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuersv1beta1.AddToScheme(scheme))
+
+	ready := func(name string, observedGeneration int64) *issuersv1beta1.GoogleCASIssuer {
+		return &issuersv1beta1.GoogleCASIssuer{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Generation: 2},
+			Status: issuerapi.IssuerStatus{Conditions: []metav1.Condition{{
+				Type:               issuerapi.IssuerConditionTypeReady,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Checked",
+				LastTransitionTime: metav1.Now(),
+				ObservedGeneration: observedGeneration,
+			}}},
+		}
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&issuersv1beta1.GoogleCASIssuer{}).
+		WithObjects(ready("current", 2), ready("outdated", 1)).
+		Build()
+
+	requests := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "current"}},
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "outdated"}},
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "deleted"}},
+	}
+	recheck := recheckIssuers(kubeClient, &issuersv1beta1.GoogleCASIssuer{}, func(context.Context, client.Object) []reconcile.Request {
+		return requests
+	})
+	assert.Equal(t, requests, recheck(t.Context(), &corev1.Secret{}))
+
+	observedGeneration := func(name string) int64 {
+		var issuer issuersv1beta1.GoogleCASIssuer
+		require.NoError(t, kubeClient.Get(t.Context(), types.NamespacedName{Namespace: "ns", Name: name}, &issuer))
+		return apimeta.FindStatusCondition(issuer.Status.Conditions, issuerapi.IssuerConditionTypeReady).ObservedGeneration
+	}
+	assert.Equal(t, int64(0), observedGeneration("current"), "issuer-lib checks issuers again whose Ready condition is outdated")
+	assert.Equal(t, int64(1), observedGeneration("outdated"))
+}
@@ -23,7 +23,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"math/rand/v2"
+	"slices"
 	"strings"
 	"time"
 
@@ -35,10 +35,20 @@ import (
 	"github.com/cert-manager/issuer-lib/controllers/signer"
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/impersonate"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -49,9 +59,40 @@ import (
 var PickedupRequestConditionType = cmapi.CertificateRequestConditionType("pickedup")
 
 type GoogleCAS struct {
-	client client.Client
+	client   client.Client
+	clients  *casClientCache
+	backoff  poolBackoff
+	degraded degradedPools
+	recorder events.EventRecorder
+
+	endpointCheck endpointCheck
 
 	MaxRetryDuration time.Duration
+
+	// ClientIdleTimeout is how long an unused CAS API client is kept open
+	// before it is closed. Defaults to 10 minutes.
+	ClientIdleTimeout time.Duration
+
+	// Endpoint overrides the address of the CAS API, e.g. to reach it
+	// through Private Service Connect. Defaults to the public endpoint.
+	Endpoint string
+
+	// InsecureEndpoint connects to Endpoint without TLS or credentials. It
+	// is meant for tests against an in-process fake, see pkg/casfake.
+	InsecureEndpoint bool
+
+	// ClusterName identifies the cluster in the labels of CAS certificates,
+	// see GoogleCASIssuerSpec.CertificateLabels.
+	ClusterName string
+
+	// TrustBundles enables publishing trust bundles, see
+	// GoogleCASIssuerSpec.TrustBundle. It needs access to ConfigMaps.
+	TrustBundles bool
+
+	// TrustBundleNamespace restricts trust bundles to ConfigMaps in this
+	// namespace, e.g. trust-manager's trust namespace. Any namespace if
+	// empty.
+	TrustBundleNamespace string
 }
 
 // SetupWithManager sets up the controller with the provided controller options
@@ -67,8 +108,14 @@ func (s *GoogleCAS) SetupWithManager(ctx context.Context, mgr ctrl.Manager, ctrl
 	}
 
 	s.client = mgr.GetClient()
+	s.recorder = mgr.GetEventRecorder(fieldOwner)
 
-	return (&controllerslib.CombinedController{
+	s.clients = newCASClientCache(s.ClientIdleTimeout)
+	if err := mgr.Add(s.clients); err != nil {
+		return err
+	}
+
+	if err := (&controllerslib.CombinedController{
 		IssuerTypes:        []issuerapi.Issuer{&issuersv1beta1.GoogleCASIssuer{}},
 		ClusterIssuerTypes: []issuerapi.Issuer{&issuersv1beta1.GoogleCASClusterIssuer{}},
 
@@ -81,8 +128,32 @@ func (s *GoogleCAS) SetupWithManager(ctx context.Context, mgr ctrl.Manager, ctrl
 
 		SetCAOnCertificateRequest: true,
 
-		EventRecorder: mgr.GetEventRecorder(fieldOwner),
-	}).SetupWithManager(ctx, mgr)
+		EventRecorder: s.recorder,
+
+		PreSetupWithManager: watchCredentialsSecrets,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		return err
+	}
+
+	if err := (&revocationController{cas: s}).SetupWithManager(ctx, mgr, ctrlOpts); err != nil {
+		return err
+	}
+
+	if !s.TrustBundles {
+		return nil
+	}
+	if err := (&trustBundleController{
+		name:      "trust-bundle-issuer",
+		cas:       s,
+		newIssuer: func() issuerapi.Issuer { return &issuersv1beta1.GoogleCASIssuer{} },
+	}).SetupWithManager(mgr, ctrlOpts); err != nil {
+		return err
+	}
+	return (&trustBundleController{
+		name:      "trust-bundle-clusterissuer",
+		cas:       s,
+		newIssuer: func() issuerapi.Issuer { return &issuersv1beta1.GoogleCASClusterIssuer{} },
+	}).SetupWithManager(mgr, ctrlOpts)
 }
 
 func (o *GoogleCAS) extractIssuerSpec(obj client.Object) (issuerSpec *issuersv1beta1.GoogleCASIssuerSpec, namespace string) {
@@ -96,59 +167,211 @@ func (o *GoogleCAS) extractIssuerSpec(obj client.Object) (issuerSpec *issuersv1b
 	panic("Program Error: Unhandled issuer type")
 }
 
-func (o *GoogleCAS) Check(ctx context.Context, issuerObj issuerapi.Issuer) error {
+// Check probes the issuer's CA pools, see probeCAPool. Failures are reported
+// with their reason in the CAPoolReady condition, see reportProbe, and
+// retried, so an issuer becomes Ready once its pool or permissions are fixed.
+// An issuer with failover targets is Ready if any of its pools passes, and
+// reports the others in its Degraded condition.
+func (o *GoogleCAS) Check(ctx context.Context, issuerObj issuerapi.Issuer) (err error) {
+	ctx, span := tracer.Start(ctx, "GoogleCAS.Check", trace.WithAttributes(issuerAttributes(issuerObj)...))
+	defer func() { endSpan(span, err) }()
+	ctx = withIssuerLogger(ctx, issuerObj)
+	defer func() { o.reportProbe(ctx, issuerObj, err) }()
+
 	issuerSpec, resourceNamespace := o.extractIssuerSpec(issuerObj)
 
-	casClient, _, err := o.createCasClient(ctx, resourceNamespace, issuerSpec)
-	if err != nil {
-		return err
+	targets := caPoolTargets(issuerSpec)
+	parents := make([]string, len(targets))
+	for i, target := range targets {
+		parent, err := buildParentString(target)
+		if err != nil {
+			return err
+		}
+		parents[i] = parent
 	}
-	casClient.Close()
 
+	casClient, release, err := o.createCasClient(ctx, resourceNamespace, issuerSpec)
+	if err != nil {
+		return probeError{reason: ReasonCredentialsUnavailable, err: err}
+	}
+	defer release()
+
+	var healthy, failed []string
+	var firstErr error
+	for i, target := range targets {
+		probeCtx, probeSpan := tracer.Start(ctx, "probeCAPool", trace.WithAttributes(attributeCAPool.String(parents[i])))
+		err := probeCAPool(probeCtx, casClient, parents[i], target)
+		endSpan(probeSpan, err)
+		if err != nil {
+			failed = append(failed, parents[i])
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		healthy = append(healthy, parents[i])
+	}
+	o.reportTargets(ctx, issuerObj, healthy, failed)
+	if len(healthy) == 0 {
+		return firstErr
+	}
 	return nil
 }
 
-// Sign implements signer.Sign for Venafi TPP and Venafi-as-a-Service.
+// Sign implements signer.Sign for Google CAS, and records its outcome in the
+// issuance metrics.
 func (o *GoogleCAS) Sign(ctx context.Context, cr signer.CertificateRequestObject, issuerObj issuerapi.Issuer) (signer.PEMBundle, error) {
+	ctx, span := tracer.Start(ctx, "GoogleCAS.Sign", trace.WithAttributes(issuerAttributes(issuerObj)...), trace.WithAttributes(
+		attributeRequestNamespace.String(cr.GetNamespace()),
+		attributeRequestName.String(cr.GetName()),
+	))
+	ctx = withIssuerLogger(ctx, issuerObj, logKeyCertificateRequest, klog.KObj(cr))
 	issuerSpec, resourceNamespace := o.extractIssuerSpec(issuerObj)
 
+	bundle, pool, err := o.sign(ctx, cr, issuerObj, issuerSpec, resourceNamespace)
+	if pool == "" {
+		pool = fmt.Sprintf("projects/%s/locations/%s/caPools/%s", issuerSpec.Project, issuerSpec.Location, issuerSpec.CaPoolId)
+	}
+	observeIssuance(cr, issuerObj, pool, bundle, err)
+	span.SetAttributes(attributeCAPool.String(pool))
+	endSpan(span, err)
+	return bundle, withTraceID(ctx, err)
+}
+
+// sign issues a certificate for cr from the first of the issuer's CA pools
+// that doesn't fail with an error that is retried, and returns the pool that
+// issued it. It only fails over from a pool that may have issued the
+// certificate once it has made sure the pool didn't, see nothingIssued.
+func (o *GoogleCAS) sign(ctx context.Context, cr signer.CertificateRequestObject, issuerObj issuerapi.Issuer, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec, resourceNamespace string) (signer.PEMBundle, string, error) {
+	log := ctrl.LoggerFrom(ctx)
+
 	details, err := cr.GetCertificateDetails()
 	if err != nil {
-		return signer.PEMBundle{}, err
+		return signer.PEMBundle{}, "", err
 	}
 
-	casClient, parent, err := o.createCasClient(ctx, resourceNamespace, issuerSpec)
+	if err := checkPolicy(issuerSpec.Policy, details); err != nil {
+		return signer.PEMBundle{}, "", signer.PermanentError{Err: err}
+	}
+
+	targets := caPoolTargets(issuerSpec)
+	parents := make([]string, len(targets))
+	for i, target := range targets {
+		parent, err := buildParentString(target)
+		if err != nil {
+			return signer.PEMBundle{}, "", signer.IssuerError{Err: err}
+		}
+		parents[i] = parent
+	}
+
+	casClient, release, err := o.createCasClient(ctx, resourceNamespace, issuerSpec)
 	if err != nil {
-		return signer.PEMBundle{}, signer.IssuerError{Err: err}
+		return signer.PEMBundle{}, "", signer.IssuerError{Err: err}
 	}
-	defer casClient.Close()
+	defer release()
 
-	createCertificateRequest := &casapi.CreateCertificateRequest{
-		Parent: parent,
-		// Should this use the certificate request name?
-		CertificateId: fmt.Sprintf("cert-manager-%d", rand.Int()),
-		Certificate: &casapi.Certificate{
-			CertificateConfig: &casapi.Certificate_PemCsr{
-				PemCsr: string(details.CSR),
-			},
-			Lifetime: &durationpb.Duration{
-				Seconds: details.Duration.Milliseconds() / 1000,
-				Nanos:   0,
-			},
-			CertificateTemplate: issuerSpec.CertificateTemplate,
-		},
-		RequestId:                     uuid.New().String(),
-		IssuingCertificateAuthorityId: issuerSpec.CertificateAuthorityId,
+	certificateTemplate, err := selectCertificateTemplate(cr, issuerSpec)
+	if err != nil {
+		return signer.PEMBundle{}, "", signer.PermanentError{Err: err}
+	}
+
+	subjectMode := subjectRequestModes[issuerSpec.SubjectMode]
+	if subjectMode == casapi.SubjectRequestMode_REFLECTED_SPIFFE {
+		if err := validateReflectedSPIFFERequest(details.CSR); err != nil {
+			return signer.PEMBundle{}, "", signer.PermanentError{Err: err}
+		}
+	}
+
+	labels, err := o.certificateLabels(cr, issuerSpec.CertificateLabels)
+	if err != nil {
+		return signer.PEMBundle{}, "", signer.PermanentError{Err: err}
 	}
 
-	createCertResp, err := casClient.CreateCertificate(ctx, createCertificateRequest)
+	certificateID, requestID := certificateIDs(cr)
+
+	var createCertResp *casapi.Certificate
+	var parent string
+	// failed are the pools that failed, and uncertain those of them that may
+	// have issued the certificate anyway.
+	var failed, uncertain []string
+	for i, target := range targets {
+		if len(uncertain) > 0 {
+			// Issuing the certificate again would leave the one a previous
+			// pool issued orphaned in CAS, so only fail over once it's clear
+			// there is none.
+			existing, existingParent, lookupErr := findCertificate(ctx, casClient, uncertain, certificateID)
+			if lookupErr != nil {
+				log.Info("Not failing over to the next CA pool, a previous one may have issued the certificate", "error", lookupErr.Error())
+				break
+			}
+			if existing != nil {
+				createCertResp, parent, err = existing, existingParent, nil
+				break
+			}
+		}
+
+		parent = parents[i]
+		targetRequestID := requestID
+		if i > 0 {
+			// Request IDs of failover targets are derived from the pool, so
+			// that CAS doesn't mistake them for retries of the same request.
+			targetRequestID = uuid.NewSHA1(uuid.MustParse(requestID), []byte(parent)).String()
+		}
+
+		createCertificateRequest := &casapi.CreateCertificateRequest{
+			Parent:        parent,
+			CertificateId: certificateID,
+			Certificate: &casapi.Certificate{
+				CertificateConfig: &casapi.Certificate_PemCsr{
+					PemCsr: string(details.CSR),
+				},
+				Lifetime: &durationpb.Duration{
+					Seconds: details.Duration.Milliseconds() / 1000,
+					Nanos:   0,
+				},
+				CertificateTemplate: certificateTemplate,
+				Labels:              labels,
+				SubjectMode:         subjectMode,
+			},
+			RequestId:                     targetRequestID,
+			IssuingCertificateAuthorityId: target.CertificateAuthorityId,
+		}
+
+		createCtx, createSpan := tracer.Start(ctx, "CreateCertificate", trace.WithAttributes(attributeCAPool.String(parent)))
+		err = o.backoff.check(parent)
+		if err == nil {
+			createCertResp, err = createOrGetCertificate(createCtx, casClient, createCertificateRequest)
+			o.backoff.record(parent, err)
+		}
+		endSpan(createSpan, err)
+		if err == nil {
+			break
+		}
+		if !errors.As(err, &retryableError{}) {
+			return signer.PEMBundle{}, parent, classifyCreateFailure(ctx, casClient, parent, target, certificateTemplate, err)
+		}
+		failed = append(failed, parent)
+		if !nothingIssued(err) {
+			uncertain = append(uncertain, parent)
+		}
+		if i < len(targets)-1 {
+			log.Info("Failing over to the next CA pool", logKeyCAPool, parent, "error", err.Error())
+		}
+	}
 	if err != nil {
-		return signer.PEMBundle{}, fmt.Errorf("casClient.CreateCertificate failed: %w", err)
+		o.reportTargets(ctx, issuerObj, nil, failed)
+		return signer.PEMBundle{}, parent, err
+	}
+	o.reportTargets(ctx, issuerObj, []string{parent}, failed)
+	log.V(1).Info("Issued CAS certificate", logKeyCASCertificate, createCertResp.GetName(), logKeyCAPool, parent)
+
+	if err := o.recordCertificate(ctx, cr, issuerSpec, parent, createCertResp); err != nil {
+		return signer.PEMBundle{}, parent, err
 	}
 
 	chainPEM, caPem, err := extractCertAndCA(createCertResp)
 	if err != nil {
-		return signer.PEMBundle{}, err
+		return signer.PEMBundle{}, parent, err
 	}
 
 	if issuerSpec.CAFetchMode == issuersv1beta1.CAFetchModePoolCAs {
@@ -156,14 +379,16 @@ func (o *GoogleCAS) Sign(ctx context.Context, cr signer.CertificateRequestObject
 		fetchCaCertsReq := &casapi.FetchCaCertsRequest{
 			CaPool: parent,
 		}
-		fetchResp, err := casClient.FetchCaCerts(ctx, fetchCaCertsReq)
+		fetchCtx, fetchSpan := tracer.Start(ctx, "FetchCaCerts", trace.WithAttributes(attributeCAPool.String(parent)))
+		fetchResp, err := casClient.FetchCaCerts(fetchCtx, fetchCaCertsReq)
+		endSpan(fetchSpan, err)
 		if err != nil {
-			return signer.PEMBundle{}, fmt.Errorf("casClient.FetchCaCerts failed: %w", err)
+			return signer.PEMBundle{}, parent, casError("FetchCaCerts", err)
 		}
 
 		filteredCA, err := filterAndDeduplicateCAs(fetchResp.CaCerts)
 		if err != nil {
-			return signer.PEMBundle{}, fmt.Errorf("filterAndDeduplicateCAs failed: %w", err)
+			return signer.PEMBundle{}, parent, fmt.Errorf("filterAndDeduplicateCAs failed: %w", err)
 		}
 		if len(filteredCA) > 0 {
 			caPem = filteredCA
@@ -173,7 +398,148 @@ func (o *GoogleCAS) Sign(ctx context.Context, cr signer.CertificateRequestObject
 	return signer.PEMBundle{
 		ChainPEM: chainPEM,
 		CAPEM:    caPem,
-	}, err
+	}, parent, err
+}
+
+// requestIDNamespace is the UUID namespace used to derive CAS request IDs.
+var requestIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://cas-issuer.jetstack.io"))
+
+// certificateIDs derives the CAS certificate ID and request ID from the UID
+// and generation of a CertificateRequest, so that every attempt to sign the
+// same request refers to the same CAS certificate.
+func certificateIDs(cr metav1.Object) (certificateID string, requestID string) {
+	seed := fmt.Sprintf("%s-%d", cr.GetUID(), cr.GetGeneration())
+	return "cert-manager-" + seed, uuid.NewSHA1(requestIDNamespace, []byte(seed)).String()
+}
+
+// createOrGetCertificate returns the certificate that was already issued for
+// req by a previous attempt, if there is one, and creates it otherwise. This
+// makes sure a retried request doesn't leave an orphaned certificate behind in
+// CAS when the response to the first attempt was lost.
+func createOrGetCertificate(ctx context.Context, casClient *privateca.CertificateAuthorityClient, req *casapi.CreateCertificateRequest) (*casapi.Certificate, error) {
+	name := fmt.Sprintf("%s/certificates/%s", req.GetParent(), req.GetCertificateId())
+
+	existing, err := casClient.GetCertificate(ctx, &casapi.GetCertificateRequest{Name: name})
+	switch status.Code(err) {
+	case codes.OK:
+		return existing, nil
+	case codes.NotFound, codes.PermissionDenied:
+		// The certificate hasn't been issued yet, or we are not allowed to
+		// look it up (roles/privateca.certificateRequester doesn't include
+		// privateca.certificates.get). In the latter case the request ID
+		// still lets CAS deduplicate recent retries.
+	default:
+		return nil, casError("GetCertificate", err)
+	}
+
+	cert, err := casClient.CreateCertificate(ctx, req)
+	if status.Code(err) == codes.AlreadyExists {
+		// A previous attempt created the certificate after we looked it up.
+		cert, err = casClient.GetCertificate(ctx, &casapi.GetCertificateRequest{Name: name})
+		if err != nil {
+			return nil, casError("GetCertificate", err)
+		}
+		return cert, nil
+	}
+	if err != nil {
+		return nil, casError("CreateCertificate", err)
+	}
+	return cert, nil
+}
+
+// classifyCreateFailure tells apart the requests CAS refused because a
+// resource they refer to is missing or in the wrong state. Those failures
+// mark the issuer as not ready if they are caused by the issuer: its CA pool,
+// permissions or CAs, see probeCAPool, or the certificate template it uses for
+// every request. Otherwise they are specific to the request, e.g. a
+// certificate template it selected that no longer exists, and fail it
+// permanently. If CAS can't be asked which it is, the request is retried.
+func classifyCreateFailure(ctx context.Context, casClient *privateca.CertificateAuthorityClient, parent string, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec, template string, err error) error {
+	if !errors.As(err, &signer.PermanentError{}) {
+		return err
+	}
+	switch status.Code(err) {
+	case codes.NotFound, codes.FailedPrecondition:
+	default:
+		return err
+	}
+
+	if probeErr := probeCAPool(ctx, casClient, parent, issuerSpec); probeErr != nil {
+		var probe probeError
+		if errors.As(probeErr, &probe) && probe.transient() {
+			return retryableError{err: probeErr}
+		}
+		return signer.IssuerError{Err: probeErr}
+	}
+	if template != "" && template == issuerSpec.CertificateTemplate {
+		_, getErr := casClient.GetCertificateTemplate(ctx, &casapi.GetCertificateTemplateRequest{Name: template})
+		switch code := status.Code(getErr); {
+		case code == codes.NotFound:
+			return signer.IssuerError{Err: casError("GetCertificateTemplate", getErr)}
+		case getErr != nil && retryableCode(code):
+			return casError("GetCertificateTemplate", getErr)
+		}
+	}
+	return err
+}
+
+// findCertificate returns the certificate with certificateID from the first
+// of pools that has it, and the pool. It returns no certificate if none of the
+// pools has it, and an error if that can't be told.
+func findCertificate(ctx context.Context, casClient *privateca.CertificateAuthorityClient, pools []string, certificateID string) (*casapi.Certificate, string, error) {
+	for _, pool := range pools {
+		name := fmt.Sprintf("%s/certificates/%s", pool, certificateID)
+		cert, err := casClient.GetCertificate(ctx, &casapi.GetCertificateRequest{Name: name})
+		switch status.Code(err) {
+		case codes.OK:
+			return cert, pool, nil
+		case codes.NotFound:
+		default:
+			return nil, "", casError("GetCertificate", err)
+		}
+	}
+	return nil, "", nil
+}
+
+// selectCertificateTemplate returns the certificate template cr selected with
+// the CertificateTemplateAnnotation, which must be one of the allowed
+// certificate templates of its issuer, or the issuer's template otherwise.
+func selectCertificateTemplate(cr metav1.Object, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) (string, error) {
+	template := cr.GetAnnotations()[CertificateTemplateAnnotation]
+	if template == "" || template == issuerSpec.CertificateTemplate {
+		return issuerSpec.CertificateTemplate, nil
+	}
+	if !slices.Contains(issuerSpec.AllowedCertificateTemplates, template) {
+		return "", fmt.Errorf("certificate template %q is not allowed by the issuer", template)
+	}
+	return template, nil
+}
+
+// subjectRequestModes maps the SubjectMode of an issuer onto CAS. Issuers
+// without one leave it to CAS.
+var subjectRequestModes = map[issuersv1beta1.SubjectMode]casapi.SubjectRequestMode{
+	issuersv1beta1.SubjectModeDefault:         casapi.SubjectRequestMode_DEFAULT,
+	issuersv1beta1.SubjectModeRDNSequence:     casapi.SubjectRequestMode_RDN_SEQUENCE,
+	issuersv1beta1.SubjectModeReflectedSPIFFE: casapi.SubjectRequestMode_REFLECTED_SPIFFE,
+}
+
+// validateReflectedSPIFFERequest checks that a CSR signed in the
+// REFLECTED_SPIFFE subject mode asks for exactly one SPIFFE ID. CAS replaces
+// the subject and SANs of the CSR with the SPIFFE ID of the issuer's
+// identity, so requests for anything else would be silently ignored.
+func validateReflectedSPIFFERequest(csrPEM []byte) error {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return errors.New("failed to decode certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate request: %w", err)
+	}
+	if len(csr.URIs) != 1 || csr.URIs[0].Scheme != "spiffe" {
+		return fmt.Errorf("the ReflectedSPIFFE subject mode requires exactly one SPIFFE ID URI SAN, got %d URI SANs", len(csr.URIs))
+	}
+	return nil
 }
 
 func buildParentString(issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) (string, error) {
@@ -192,41 +558,132 @@ func buildParentString(issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) (string,
 	return parent, nil
 }
 
-func (c *GoogleCAS) createCasClient(ctx context.Context, resourceNamespace string, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) (*privateca.CertificateAuthorityClient, string, error) {
-	parent, err := buildParentString(issuerSpec)
-	if err != nil {
-		return nil, "", err
+// createCasClient returns a CAS API client for the credentials configured on
+// the issuer. Clients are shared between calls that use the same credentials;
+// the returned release func must be called once the client is no longer used.
+func (c *GoogleCAS) createCasClient(ctx context.Context, resourceNamespace string, issuerSpec *issuersv1beta1.GoogleCASIssuerSpec) (_ *privateca.CertificateAuthorityClient, _ func(), err error) {
+	ctx, span := tracer.Start(ctx, "createCasClient")
+	defer func() { endSpan(span, err) }()
+
+	var key casClientKey
+	var opts []option.ClientOption
+	workloadIdentity := issuerSpec.WorkloadIdentityFederation
+	if workloadIdentity != nil && len(issuerSpec.Credentials.Name) > 0 {
+		return nil, nil, fmt.Errorf("credentials and workloadIdentityFederation are mutually exclusive")
 	}
-
-	var casClient *privateca.CertificateAuthorityClient
-	if len(issuerSpec.Credentials.Name) > 0 && len(issuerSpec.Credentials.Key) > 0 {
+	if c.client == nil && (workloadIdentity != nil || len(issuerSpec.Credentials.Name) > 0) {
+		// Signing outside a cluster, e.g. with the sign command.
+		return nil, nil, fmt.Errorf("credentials and workloadIdentityFederation need a cluster, use Application Default Credentials instead")
+	}
+	if workloadIdentity != nil {
+		key.workloadIdentity = workloadIdentityKey(resourceNamespace, workloadIdentity)
+	} else if len(issuerSpec.Credentials.Name) > 0 && len(issuerSpec.Credentials.Key) > 0 {
 		secretNamespaceName := types.NamespacedName{
 			Name:      issuerSpec.Credentials.Name,
 			Namespace: resourceNamespace,
 		}
 		var secret corev1.Secret
-		if err := c.client.Get(ctx, secretNamespaceName, &secret); err != nil {
-			return nil, "", err
+		secretCtx, secretSpan := tracer.Start(ctx, "getCredentialsSecret")
+		err := c.client.Get(secretCtx, secretNamespaceName, &secret)
+		endSpan(secretSpan, err)
+		if err != nil {
+			return nil, nil, err
 		}
 		credentials, exists := secret.Data[issuerSpec.Credentials.Key]
 		if !exists {
-			return nil, "", fmt.Errorf("no credentials found in secret %s under %s", secretNamespaceName, issuerSpec.Credentials.Key)
+			return nil, nil, fmt.Errorf("no credentials found in secret %s under %s", secretNamespaceName, issuerSpec.Credentials.Key)
+		}
+		key = casClientKey{
+			secretNamespace:       secret.Namespace,
+			secretName:            secret.Name,
+			secretKey:             issuerSpec.Credentials.Key,
+			secretResourceVersion: secret.ResourceVersion,
+		}
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	}
+	// Otherwise use implicit credentials, e.g. with Google cloud service accounts
+
+	impersonation := issuerSpec.Impersonate
+	if impersonation != nil {
+		key.impersonation = impersonationKey(impersonation)
+	}
+
+	newClient := func(ctx context.Context) (*privateca.CertificateAuthorityClient, error) {
+		opts := slices.Clone(opts)
+		if c.InsecureEndpoint {
+			// The fake doesn't check credentials, and they can't be
+			// combined with option.WithoutAuthentication.
+			opts = []option.ClientOption{
+				option.WithoutAuthentication(),
+				option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			}
+		} else if workloadIdentity != nil {
+			tokenSource, err := workloadIdentityTokenSource(ctx, c.client, resourceNamespace, workloadIdentity)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure workload identity federation: %w", err)
+			}
+			opts = []option.ClientOption{option.WithTokenSource(tokenSource)}
+		}
+		if impersonation != nil && !c.InsecureEndpoint {
+			tokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonationCredentialsConfig(impersonation), opts...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to impersonate %s: %w", impersonation.TargetPrincipal, err)
+			}
+			opts = []option.ClientOption{option.WithTokenSource(tokenSource)}
 		}
-		c, err := privateca.NewCertificateAuthorityClient(ctx, option.WithCredentialsJSON(credentials))
+
+		if c.Endpoint != "" {
+			opts = append(opts, option.WithEndpoint(c.Endpoint))
+		}
+		opts = append(opts,
+			option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(casMetricsInterceptor)),
+			option.WithGRPCDialOption(grpc.WithStatsHandler(otelgrpc.NewClientHandler())),
+		)
+
+		casClient, err := privateca.NewCertificateAuthorityClient(ctx, opts...)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to build certificate authority client: %w", err)
+			return nil, fmt.Errorf("failed to build certificate authority client: %w", err)
 		}
-		casClient = c
-	} else {
-		// Using implicit credentials, e.g. with Google cloud service accounts
-		c, err := privateca.NewCertificateAuthorityClient(ctx)
+		return casClient, nil
+	}
+
+	if c.clients == nil {
+		casClient, err := newClient(ctx)
 		if err != nil {
-			return nil, "", err
+			return nil, nil, err
 		}
-		casClient = c
+		return casClient, func() { casClient.Close() }, nil
+	}
+
+	return c.clients.get(ctx, key, newClient)
+}
+
+// defaultImpersonationScopes are the OAuth scopes of impersonated tokens when
+// the issuer doesn't set any.
+var defaultImpersonationScopes = []string{"https://www.googleapis.com/auth/cloud-platform"}
+
+func impersonationCredentialsConfig(config *issuersv1beta1.ImpersonationConfig) impersonate.CredentialsConfig {
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultImpersonationScopes
 	}
+	return impersonate.CredentialsConfig{
+		TargetPrincipal: config.TargetPrincipal,
+		Delegates:       config.Delegates,
+		Scopes:          scopes,
+	}
+}
 
-	return casClient, parent, nil
+// impersonationKey identifies an impersonation config in a casClientKey, so
+// that issuers impersonating different principals, or the same principal
+// through different delegates or scopes, never share a client.
+func impersonationKey(config *issuersv1beta1.ImpersonationConfig) string {
+	cfg := impersonationCredentialsConfig(config)
+	return strings.Join([]string{
+		cfg.TargetPrincipal,
+		strings.Join(cfg.Delegates, ","),
+		strings.Join(cfg.Scopes, " "),
+	}, "|")
 }
 
 // extractCertAndCA takes a response from the Google CAS API and formats it into a format
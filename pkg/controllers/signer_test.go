@@ -17,6 +17,8 @@ limitations under the License.
 package controllers
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -25,14 +27,27 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/cert-manager/google-cas-issuer/api/v1beta1"
+	"github.com/cert-manager/google-cas-issuer/pkg/casfake"
 )
 
 func TestBuildParentString(t *testing.T) {
@@ -65,6 +80,32 @@ func TestBuildParentStringMissingPoolId(t *testing.T) {
 	}
 }
 
+func TestCertificateIDs(t *testing.T) {
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:        "1b4e28ba-2fa1-11d2-883f-0016d3cca427",
+			Generation: 1,
+		},
+	}
+
+	certificateID, requestID := certificateIDs(cr)
+	assert.Equal(t, "cert-manager-1b4e28ba-2fa1-11d2-883f-0016d3cca427-1", certificateID)
+	assert.Regexp(t, `^[a-zA-Z0-9_-]{1,63}$`, certificateID)
+	parsed, err := uuid.Parse(requestID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, parsed)
+
+	retriedCertificateID, retriedRequestID := certificateIDs(cr.DeepCopy())
+	assert.Equal(t, certificateID, retriedCertificateID, "retries must reuse the certificate ID")
+	assert.Equal(t, requestID, retriedRequestID, "retries must reuse the request ID")
+
+	other := cr.DeepCopy()
+	other.Generation = 2
+	otherCertificateID, otherRequestID := certificateIDs(other)
+	assert.NotEqual(t, certificateID, otherCertificateID)
+	assert.NotEqual(t, requestID, otherRequestID)
+}
+
 func TestExtractCertAndCA(t *testing.T) {
 	type expected struct {
 		cert []byte
@@ -295,3 +336,278 @@ func generateTestCert(t *testing.T, isCA bool, subject, issuer string, expiry ti
 
 	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
 }
+
+func TestImpersonationCredentialsConfig(t *testing.T) {
+	cfg := impersonationCredentialsConfig(&v1beta1.ImpersonationConfig{
+		TargetPrincipal: "tenant@project.iam.gserviceaccount.com",
+	})
+	assert.Equal(t, "tenant@project.iam.gserviceaccount.com", cfg.TargetPrincipal)
+	assert.Equal(t, defaultImpersonationScopes, cfg.Scopes)
+
+	cfg = impersonationCredentialsConfig(&v1beta1.ImpersonationConfig{
+		TargetPrincipal: "tenant@project.iam.gserviceaccount.com",
+		Delegates:       []string{"delegate@project.iam.gserviceaccount.com"},
+		Scopes:          []string{"https://www.googleapis.com/auth/cloud-platform.read-only"},
+	})
+	assert.Equal(t, []string{"delegate@project.iam.gserviceaccount.com"}, cfg.Delegates)
+	assert.Equal(t, []string{"https://www.googleapis.com/auth/cloud-platform.read-only"}, cfg.Scopes)
+}
+
+func TestImpersonationKey(t *testing.T) {
+	target := &v1beta1.ImpersonationConfig{TargetPrincipal: "tenant@project.iam.gserviceaccount.com"}
+	withDefaultScopes := &v1beta1.ImpersonationConfig{
+		TargetPrincipal: "tenant@project.iam.gserviceaccount.com",
+		Scopes:          defaultImpersonationScopes,
+	}
+	viaDelegate := &v1beta1.ImpersonationConfig{
+		TargetPrincipal: "tenant@project.iam.gserviceaccount.com",
+		Delegates:       []string{"delegate@project.iam.gserviceaccount.com"},
+	}
+	other := &v1beta1.ImpersonationConfig{TargetPrincipal: "other@project.iam.gserviceaccount.com"}
+
+	assert.Equal(t, impersonationKey(target), impersonationKey(withDefaultScopes))
+	assert.NotEqual(t, impersonationKey(target), impersonationKey(viaDelegate))
+	assert.NotEqual(t, impersonationKey(target), impersonationKey(other))
+}
+
+// newFakeCAS starts a fake CAS with a pool holding one enabled CA, and
+// returns it with a GoogleCAS connected to it.
+func newFakeCAS(t *testing.T) (*casfake.Server, *GoogleCAS, *v1beta1.GoogleCASIssuerSpec) {
+	t.Helper()
+
+	fake := casfake.New()
+	pool := fake.AddCaPool("my-project", "us-east1", "my-pool")
+	_, err := fake.AddCertificateAuthority(pool, "root-1", casapi.CertificateAuthority_ENABLED)
+	require.NoError(t, err)
+
+	addr, err := fake.Start()
+	require.NoError(t, err)
+	t.Cleanup(fake.Stop)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	kubeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1beta1.GoogleCASIssuer{}, &v1beta1.GoogleCASClusterIssuer{}).
+		Build()
+
+	return fake, &GoogleCAS{client: kubeClient, Endpoint: addr, InsecureEndpoint: true}, &v1beta1.GoogleCASIssuerSpec{
+		Project:  "my-project",
+		Location: "us-east1",
+		CaPoolId: "my-pool",
+	}
+}
+
+func testCSR(t *testing.T, dnsName string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: dnsName},
+		DNSNames: []string{dnsName},
+	}, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func testCertificateRequest(t *testing.T, uid types.UID, dnsName string) signer.CertificateRequestObject {
+	t.Helper()
+
+	return signer.CertificateRequestObjectFromCertificateRequest(&cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns", UID: uid, CreationTimestamp: metav1.Now()},
+		Spec: cmapi.CertificateRequestSpec{
+			Request:  testCSR(t, dnsName),
+			Duration: &metav1.Duration{Duration: 24 * time.Hour},
+		},
+	})
+}
+
+func TestSign(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+	cr := testCertificateRequest(t, "b9f0a3b2-7d3e-4d55-9c0a-4a3c3b0e6a11", "example.com")
+
+	bundle, err := cas.Sign(t.Context(), cr, issuer)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(bundle.ChainPEM)
+	require.NotNil(t, block)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, leaf.DNSNames)
+	assert.Equal(t, 24*time.Hour, leaf.NotAfter.Sub(leaf.NotBefore))
+	assert.Equal(t, "root-1", leaf.Issuer.CommonName)
+	assert.Contains(t, string(bundle.CAPEM), "BEGIN CERTIFICATE")
+
+	// Signing the same request again returns the certificate issued before.
+	again, err := cas.Sign(t.Context(), cr, issuer)
+	require.NoError(t, err)
+	assert.Equal(t, bundle.ChainPEM, again.ChainPEM)
+	assert.Len(t, fake.Certificates(), 1)
+	assert.Equal(t, 1, fake.Calls("CreateCertificate"))
+}
+
+func TestSignWithoutCluster(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	cas.client = nil
+	spec.FailoverTargets = []v1beta1.CAPoolTarget{{Project: "my-project", Location: "us-east1", CaPoolId: "missing"}}
+	issuer := &v1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer"}, Spec: *spec}
+
+	_, err := cas.Sign(t.Context(), testCertificateRequest(t, "6e2b9d14-3c7a-4f8e-9b51-0a4d2e8c7f36", "example.com"), issuer)
+	require.NoError(t, err)
+	assert.Len(t, fake.Certificates(), 1)
+
+	issuer.Spec.Credentials.Name = "credentials"
+	issuer.Spec.Credentials.Key = "key.json"
+	_, err = cas.Sign(t.Context(), testCertificateRequest(t, "1f8a5c3e-7b2d-4e69-a0c4-9d3e6b1f2a58", "example.com"), issuer)
+	assert.ErrorContains(t, err, "use Application Default Credentials instead")
+}
+
+func TestSignPoolCAs(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	_, err := fake.AddCertificateAuthority("projects/my-project/locations/us-east1/caPools/my-pool", "root-2", casapi.CertificateAuthority_STAGED)
+	require.NoError(t, err)
+	spec.CAFetchMode = v1beta1.CAFetchModePoolCAs
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+
+	bundle, err := cas.Sign(t.Context(), testCertificateRequest(t, "0d7c8f0e-2a55-4e5b-8a44-5d1f1b7c2f90", "example.com"), issuer)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(bundle.CAPEM), "BEGIN CERTIFICATE"), "the CA bundle must hold every CA in the pool")
+}
+
+func TestSignErrors(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	spec.CertificateTemplate = "projects/my-project/locations/us-east1/certificateTemplates/missing"
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+
+	_, err := cas.Sign(t.Context(), testCertificateRequest(t, "4a1e9c66-1d3b-4f0e-b0a8-6a8e0f3d2c55", "example.com"), issuer)
+	assert.ErrorAs(t, err, &signer.IssuerError{}, "a missing issuer template makes the issuer not ready")
+
+	// A missing template that only one request selected fails that request.
+	spec.CertificateTemplate = ""
+	spec.AllowedCertificateTemplates = []string{"projects/my-project/locations/us-east1/certificateTemplates/missing"}
+	issuer.Spec = *spec
+	cr := testCertificateRequest(t, "2d6f0b8e-5a1c-4e37-9f24-c8b1d3e7a690", "example.com")
+	cr.SetAnnotations(map[string]string{CertificateTemplateAnnotation: spec.AllowedCertificateTemplates[0]})
+	_, err = cas.Sign(t.Context(), cr, issuer)
+	assert.ErrorAs(t, err, &signer.PermanentError{})
+	assert.NotErrorAs(t, err, &signer.IssuerError{})
+
+	// A CA pool that is gone makes the issuer not ready.
+	issuer.Spec.CaPoolId = "missing"
+	_, err = cas.Sign(t.Context(), testCertificateRequest(t, "8b3e1f57-0c9d-4a26-b7e4-5f2a9d1c6e03", "example.com"), issuer)
+	assert.ErrorAs(t, err, &signer.IssuerError{})
+	assert.ErrorContains(t, err, ReasonCAPoolNotFound)
+
+	// If CAS is unavailable while looking into why it refused a request,
+	// only the request is retried.
+	issuer.Spec = *spec
+	fake.SetError("CreateCertificate", status.Error(codes.FailedPrecondition, "no enabled CA"))
+	fake.SetError("TestIamPermissions", status.Error(codes.Unavailable, "try again"))
+	_, err = cas.Sign(t.Context(), testCertificateRequest(t, "5e9a2c74-6b1d-4f83-a0c5-d7e3f1b8a942", "example.com"), issuer)
+	assert.ErrorAs(t, err, &retryableError{})
+	assert.NotErrorAs(t, err, &signer.IssuerError{})
+	assert.NotErrorAs(t, err, &signer.PermanentError{})
+	fake.SetError("TestIamPermissions", nil)
+
+	fake.SetError("CreateCertificate", status.Error(codes.Aborted, "try again"))
+	_, err = cas.Sign(t.Context(), testCertificateRequest(t, "7c2b5d1a-93e4-4b8f-a6d2-1e0f9b3c4d77", "example.com"), issuer)
+	assert.ErrorAs(t, err, &retryableError{})
+	assert.Empty(t, fake.Certificates())
+}
+
+func TestSignReflectedSPIFFE(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	spec.SubjectMode = v1beta1.SubjectModeReflectedSPIFFE
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+
+	spiffeCSR := func(uris ...string) signer.CertificateRequestObject {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.CertificateRequest{}
+		for _, uri := range uris {
+			parsed, err := url.Parse(uri)
+			require.NoError(t, err)
+			template.URIs = append(template.URIs, parsed)
+		}
+		der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+		require.NoError(t, err)
+		return signer.CertificateRequestObjectFromCertificateRequest(&cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "cr", Namespace: "ns", UID: types.UID(uuid.NewString()), CreationTimestamp: metav1.Now()},
+			Spec: cmapi.CertificateRequestSpec{
+				Request:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+				Duration: &metav1.Duration{Duration: time.Hour},
+			},
+		})
+	}
+
+	bundle, err := cas.Sign(t.Context(), spiffeCSR("spiffe://example.org/ns/ns/sa/workload"), issuer)
+	require.NoError(t, err)
+	block, _ := pem.Decode(bundle.ChainPEM)
+	require.NotNil(t, block)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.Len(t, leaf.URIs, 1)
+	assert.Equal(t, "spiffe://example.org/ns/ns/sa/workload", leaf.URIs[0].String())
+	require.Len(t, fake.Certificates(), 1)
+	assert.Equal(t, casapi.SubjectRequestMode_REFLECTED_SPIFFE, fake.Certificates()[0].GetSubjectMode())
+
+	for name, uris := range map[string][]string{
+		"no SPIFFE ID":    nil,
+		"two SPIFFE IDs":  {"spiffe://example.org/a", "spiffe://example.org/b"},
+		"not a SPIFFE ID": {"https://example.org/a"},
+	} {
+		_, err := cas.Sign(t.Context(), spiffeCSR(uris...), issuer)
+		assert.ErrorAs(t, err, &signer.PermanentError{}, name)
+	}
+	assert.Len(t, fake.Certificates(), 1, "invalid requests aren't sent to CAS")
+}
+
+func TestSignCertificateTemplateOverride(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	server := fake.AddCertificateTemplate("my-project", "us-east1", "server")
+	clientTemplate := fake.AddCertificateTemplate("my-project", "us-east1", "client")
+	spec.CertificateTemplate = server
+	spec.AllowedCertificateTemplates = []string{clientTemplate}
+	issuer := &v1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns"}, Spec: *spec}
+
+	tests := map[string]struct {
+		template     string
+		wantTemplate string
+		wantErr      bool
+	}{
+		"issuer template":            {wantTemplate: server},
+		"issuer template selected":   {template: server, wantTemplate: server},
+		"allowed template":           {template: clientTemplate, wantTemplate: clientTemplate},
+		"template outside allowlist": {template: "projects/my-project/locations/us-east1/certificateTemplates/ca", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := testCertificateRequest(t, types.UID(uuid.NewString()), "example.com")
+			if tt.template != "" {
+				cr.SetAnnotations(map[string]string{CertificateTemplateAnnotation: tt.template})
+			}
+			before := len(fake.Certificates())
+
+			_, err := cas.Sign(t.Context(), cr, issuer)
+			if tt.wantErr {
+				assert.ErrorAs(t, err, &signer.PermanentError{})
+				assert.Len(t, fake.Certificates(), before)
+				return
+			}
+			require.NoError(t, err)
+			certificateID, _ := certificateIDs(cr)
+			for _, certificate := range fake.Certificates() {
+				if strings.HasSuffix(certificate.GetName(), "/"+certificateID) {
+					assert.Equal(t, tt.wantTemplate, certificate.GetCertificateTemplate())
+					return
+				}
+			}
+			t.Fatalf("certificate %s was not issued", certificateID)
+		})
+	}
+}
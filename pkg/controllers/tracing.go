@@ -0,0 +1,95 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer traces the steps of signing requests and checking issuers. Spans
+// are only exported if the global tracer provider is set, see --tracing-exporter.
+var tracer = otel.Tracer("github.com/cert-manager/google-cas-issuer/pkg/controllers")
+
+// Span attribute keys. The issuer attributes match the labels of the
+// issuance metrics.
+const (
+	attributeIssuerKind       = attribute.Key("cas_issuer.issuer_kind")
+	attributeIssuerNamespace  = attribute.Key("cas_issuer.issuer_namespace")
+	attributeIssuerName       = attribute.Key("cas_issuer.issuer_name")
+	attributeRequestNamespace = attribute.Key("cas_issuer.request_namespace")
+	attributeRequestName      = attribute.Key("cas_issuer.request_name")
+	attributeCAPool           = attribute.Key("cas_issuer.ca_pool")
+)
+
+// issuerAttributes identify an issuer on a span.
+func issuerAttributes(issuerObj issuerapi.Issuer) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attributeIssuerKind.String(issuerKind(issuerObj)),
+		attributeIssuerNamespace.String(issuerObj.GetNamespace()),
+		attributeIssuerName.String(issuerObj.GetName()),
+	}
+}
+
+// endSpan records err on span, if it isn't nil, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceSuffix returns a suffix for event notes that names the trace of ctx,
+// so that events can be correlated with traces. It is empty if ctx isn't
+// traced.
+func traceSuffix(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsSampled() {
+		return ""
+	}
+	return " (trace ID " + spanContext.TraceID().String() + ")"
+}
+
+// tracedError adds the trace ID of a failed request to its error, so that the
+// events and conditions issuer-lib reports for the failure name the trace.
+type tracedError struct {
+	err    error
+	suffix string
+}
+
+func (e tracedError) Error() string {
+	return e.err.Error() + e.suffix
+}
+
+func (e tracedError) Unwrap() error {
+	return e.err
+}
+
+// withTraceID wraps err in a tracedError if ctx is traced.
+func withTraceID(ctx context.Context, err error) error {
+	suffix := traceSuffix(ctx)
+	if err == nil || suffix == "" {
+		return err
+	}
+	return tracedError{err: err, suffix: suffix}
+}
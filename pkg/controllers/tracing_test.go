@@ -0,0 +1,132 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/cert-manager/issuer-lib/controllers/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// spanRecorder installs a global tracer provider that records every span.
+// tracer delegates to the first global provider, so it is only installed
+// once, and tests select their spans by trace ID.
+var spanRecorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+})
+
+// startTrace starts a trace for a test, and returns a func that ends it and
+// returns its spans by name.
+func startTrace(t *testing.T) (context.Context, trace.TraceID, func() map[string]sdktrace.ReadOnlySpan) {
+	recorder := spanRecorder()
+	ctx, root := otel.Tracer("test").Start(t.Context(), t.Name())
+	traceID := root.SpanContext().TraceID()
+	return ctx, traceID, func() map[string]sdktrace.ReadOnlySpan {
+		root.End()
+		spans := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			if span.SpanContext().TraceID() == traceID {
+				spans[span.Name()] = span
+			}
+		}
+		return spans
+	}
+}
+
+func TestSignTracing(t *testing.T) {
+	_, cas, spec := newFakeCAS(t)
+	recorder := &events.FakeRecorder{Events: make(chan string, 10)}
+	cas.recorder = recorder
+
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"}, Spec: *spec}
+	cr := testCertificateRequestFor(t, "default", "cert-1", cmmetav1.IssuerReference{
+		Group: issuersv1beta1.GroupVersion.Group,
+		Kind:  "GoogleCASIssuer",
+		Name:  "issuer",
+	})
+	cr.UID = "cr-uid"
+	require.NoError(t, cas.client.Create(t.Context(), cr))
+
+	ctx, traceID, spans := startTrace(t)
+	_, err := cas.Sign(ctx, signer.CertificateRequestObjectFromCertificateRequest(cr), issuer)
+	require.NoError(t, err)
+
+	recorded := spans()
+	for _, name := range []string{"GoogleCAS.Sign", "createCasClient", "CreateCertificate", "recordCertificate"} {
+		assert.Contains(t, recorded, name)
+	}
+	assert.Contains(t, recorded, "google.cloud.security.privateca.v1.CertificateAuthorityService/CreateCertificate", "CAS calls are traced")
+	if sign, ok := recorded["GoogleCAS.Sign"]; ok {
+		assert.Contains(t, sign.Attributes(), attributeRequestName.String("cert-1"))
+		assert.Contains(t, sign.Attributes(), attributeCAPool.String("projects/my-project/locations/us-east1/caPools/my-pool"))
+	}
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "(trace ID "+traceID.String()+")")
+}
+
+func TestSignTracingError(t *testing.T) {
+	_, cas, spec := newFakeCAS(t)
+	spec.Policy = &issuersv1beta1.IssuancePolicy{AllowedDNSNames: []string{"*.example.org"}}
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"}, Spec: *spec}
+
+	ctx, traceID, _ := startTrace(t)
+	_, err := cas.Sign(ctx, testCertificateRequest(t, "0c3e9f7a-5b21-4d8e-9a6f-2e4b7c1d8f30", "www.example.com"), issuer)
+	require.ErrorAs(t, err, &signer.PermanentError{}, "wrapping keeps the error's type")
+	assert.ErrorContains(t, err, "(trace ID "+traceID.String()+")")
+
+}
+
+func TestCheckTracingError(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	fake.SetError("GetCaPool", status.Error(codes.NotFound, "pool not found"))
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "default"}, Spec: *spec}
+
+	ctx, _, spans := startTrace(t)
+	require.Error(t, cas.Check(ctx, issuer))
+
+	recorded := spans()
+	require.Contains(t, recorded, "GoogleCAS.Check")
+	assert.Equal(t, otelcodes.Error, recorded["GoogleCAS.Check"].Status().Code)
+	require.Contains(t, recorded, "probeCAPool")
+	assert.Equal(t, otelcodes.Error, recorded["probeCAPool"].Status().Code)
+}
+
+func TestTraceSuffix(t *testing.T) {
+	assert.Empty(t, traceSuffix(t.Context()), "untraced requests have no suffix")
+	err := errors.New("failed")
+	assert.Equal(t, err, withTraceID(t.Context(), err), "errors of untraced requests are unchanged")
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// TrustBundleLabel marks the trust bundle ConfigMaps published into the trust
+// namespace of trust-manager, so that Bundles can select them as a source.
+const TrustBundleLabel = "cas-issuer.jetstack.io/trust-bundle"
+
+const (
+	defaultTrustBundleKey             = "ca.crt"
+	defaultTrustBundleRefreshInterval = time.Hour
+	minTrustBundleRefreshInterval     = time.Minute
+)
+
+// trustBundleController publishes the root certificates of the CA pools of
+// the issuers of one kind into ConfigMaps, see GoogleCASIssuerSpec.TrustBundle.
+type trustBundleController struct {
+	name      string
+	cas       *GoogleCAS
+	client    client.Client
+	reader    client.Reader
+	recorder  events.EventRecorder
+	newIssuer func() issuerapi.Issuer
+}
+
+func (r *trustBundleController) SetupWithManager(mgr ctrl.Manager, ctrlOpts controller.Options) error {
+	r.client = mgr.GetClient()
+	// ConfigMaps are read directly, and only their metadata is watched, so
+	// that the manager doesn't cache every ConfigMap in the cluster.
+	r.reader = mgr.GetAPIReader()
+	r.recorder = mgr.GetEventRecorder("cas-issuer.jetstack.io")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(r.name).
+		For(r.newIssuer(), builder.WithPredicates(predicate.GenerationChangedPredicate{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			issuerSpec, _ := r.cas.extractIssuerSpec(obj)
+			return issuerSpec.TrustBundle != nil
+		}))).
+		// Restore trust bundles that are edited or deleted.
+		Owns(&corev1.ConfigMap{}, builder.OnlyMetadata).
+		WithOptions(ctrlOpts).
+		Complete(r)
+}
+
+// Reconcile publishes the root certificates of an issuer's CA pools, and
+// requeues the issuer to refresh them after its refresh interval.
+func (r *trustBundleController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	issuerObj := r.newIssuer()
+	if err := r.client.Get(ctx, req.NamespacedName, issuerObj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	issuerSpec, resourceNamespace := r.cas.extractIssuerSpec(issuerObj)
+	trustBundle := issuerSpec.TrustBundle
+	if trustBundle == nil {
+		return ctrl.Result{}, nil
+	}
+	ctx = withIssuerLogger(ctx, issuerObj)
+
+	if err := r.publish(ctx, issuerObj, trustBundle, resourceNamespace); err != nil {
+		r.recorder.Eventf(issuerObj, nil, corev1.EventTypeWarning, "TrustBundleFailed", "PublishTrustBundle", "Failed to publish the trust bundle: %v", err)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: trustBundleRefreshInterval(trustBundle)}, nil
+}
+
+// publish writes the root certificates of the CA pools of issuerObj to its
+// trust bundle ConfigMap, creating it if needed. A ConfigMap of the same name
+// that the issuer doesn't own is left alone.
+func (r *trustBundleController) publish(ctx context.Context, issuerObj issuerapi.Issuer, trustBundle *issuersv1beta1.TrustBundle, resourceNamespace string) error {
+	trustManager := forTrustManager(issuerObj, trustBundle)
+	key := types.NamespacedName{Namespace: resourceNamespace, Name: trustBundle.ConfigMapName}
+	if trustManager {
+		key.Namespace = trustBundle.TrustManagerNamespace
+	}
+	if namespace := r.cas.TrustBundleNamespace; namespace != "" && key.Namespace != namespace {
+		return fmt.Errorf("ConfigMap %s is not in namespace %s, the only one trust bundles are published to", key, namespace)
+	}
+	dataKey := trustBundleKey(trustBundle)
+
+	roots, err := r.cas.fetchRoots(ctx, issuerObj)
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		return errors.New("the CA pools have no unexpired root certificates")
+	}
+
+	var configMap corev1.ConfigMap
+	exists := true
+	err = r.reader.Get(ctx, key, &configMap)
+	switch {
+	case apierrors.IsNotFound(err):
+		configMap = corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		exists = false
+	case err != nil:
+		return err
+	case !metav1.IsControlledBy(&configMap, issuerObj):
+		return fmt.Errorf("ConfigMap %s already exists and is not owned by the issuer", key)
+	case configMap.Data[dataKey] == string(roots) && (!trustManager || configMap.Labels[TrustBundleLabel] == "true"):
+		return nil
+	}
+
+	patch := client.MergeFromWithOptions(configMap.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[dataKey] = string(roots)
+	if trustManager {
+		metav1.SetMetaDataLabel(&configMap.ObjectMeta, TrustBundleLabel, "true")
+	}
+	if !exists {
+		if err := controllerutil.SetControllerReference(issuerObj, &configMap, r.client.Scheme()); err != nil {
+			return err
+		}
+		err = r.client.Create(ctx, &configMap)
+	} else {
+		err = r.client.Patch(ctx, &configMap, patch)
+	}
+	if err != nil {
+		return err
+	}
+
+	count := bytes.Count(roots, []byte("-----BEGIN CERTIFICATE-----"))
+	ctrl.LoggerFrom(ctx).Info("Published trust bundle", "configMap", key, "rootCertificates", count)
+	r.recorder.Eventf(issuerObj, nil, corev1.EventTypeNormal, "TrustBundlePublished", "PublishTrustBundle", "Published %d root certificates to ConfigMap %s", count, key)
+	return nil
+}
+
+// fetchRoots returns the unexpired, deduplicated root certificates of all the
+// CA pools of an issuer, including its failover targets.
+func (o *GoogleCAS) fetchRoots(ctx context.Context, issuerObj issuerapi.Issuer) ([]byte, error) {
+	issuerSpec, resourceNamespace := o.extractIssuerSpec(issuerObj)
+
+	casClient, release, err := o.createCasClient(ctx, resourceNamespace, issuerSpec)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var chains []*casapi.FetchCaCertsResponse_CertChain
+	for _, target := range caPoolTargets(issuerSpec) {
+		parent, err := buildParentString(target)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := casClient.FetchCaCerts(ctx, &casapi.FetchCaCertsRequest{CaPool: parent})
+		if err != nil {
+			return nil, casError("FetchCaCerts", err)
+		}
+		chains = append(chains, resp.CaCerts...)
+	}
+	return filterAndDeduplicateCAs(chains)
+}
+
+// forTrustManager reports whether the trust bundle of an issuer is published
+// into the trust namespace of trust-manager, which only cluster issuers may
+// do.
+func forTrustManager(issuerObj issuerapi.Issuer, trustBundle *issuersv1beta1.TrustBundle) bool {
+	_, ok := issuerObj.(*issuersv1beta1.GoogleCASClusterIssuer)
+	return ok && trustBundle.TrustManagerNamespace != ""
+}
+
+func trustBundleKey(trustBundle *issuersv1beta1.TrustBundle) string {
+	if trustBundle.Key != "" {
+		return trustBundle.Key
+	}
+	return defaultTrustBundleKey
+}
+
+func trustBundleRefreshInterval(trustBundle *issuersv1beta1.TrustBundle) time.Duration {
+	if trustBundle.RefreshInterval == nil {
+		return defaultTrustBundleRefreshInterval
+	}
+	return max(trustBundle.RefreshInterval.Duration, minTrustBundleRefreshInterval)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	casapi "cloud.google.com/go/security/privateca/apiv1/privatecapb"
+	issuerapi "github.com/cert-manager/issuer-lib/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestTrustBundleRefreshInterval(t *testing.T) {
+	assert.Equal(t, time.Hour, trustBundleRefreshInterval(&issuersv1beta1.TrustBundle{}))
+	assert.Equal(t, 10*time.Minute, trustBundleRefreshInterval(&issuersv1beta1.TrustBundle{RefreshInterval: &metav1.Duration{Duration: 10 * time.Minute}}))
+	assert.Equal(t, time.Minute, trustBundleRefreshInterval(&issuersv1beta1.TrustBundle{RefreshInterval: &metav1.Duration{Duration: time.Second}}))
+}
+
+func TestTrustBundleController(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	pool := fake.AddCaPool("my-project", "europe-west1", "my-pool-eu")
+	_, err := fake.AddCertificateAuthority(pool, "root-eu", casapi.CertificateAuthority_ENABLED)
+	require.NoError(t, err)
+	spec.FailoverTargets = []issuersv1beta1.CAPoolTarget{{Project: "my-project", Location: "europe-west1", CaPoolId: "my-pool-eu"}}
+	spec.TrustBundle = &issuersv1beta1.TrustBundle{ConfigMapName: "my-pool-roots", TrustManagerNamespace: "cert-manager"}
+	kubeClient := cas.client
+
+	issuer := &issuersv1beta1.GoogleCASClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", UID: "issuer-uid"}, Spec: *spec}
+	require.NoError(t, kubeClient.Create(t.Context(), issuer))
+
+	recorder := &events.FakeRecorder{Events: make(chan string, 10)}
+	r := &trustBundleController{
+		cas:       cas,
+		client:    kubeClient,
+		reader:    kubeClient,
+		recorder:  recorder,
+		newIssuer: func() issuerapi.Issuer { return &issuersv1beta1.GoogleCASClusterIssuer{} },
+	}
+	result, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+
+	var configMap corev1.ConfigMap
+	require.NoError(t, kubeClient.Get(t.Context(), types.NamespacedName{Namespace: "cert-manager", Name: "my-pool-roots"}, &configMap))
+	assert.Equal(t, "true", configMap.Labels[TrustBundleLabel])
+	assert.True(t, metav1.IsControlledBy(&configMap, issuer))
+	assert.Equal(t, 2, strings.Count(configMap.Data["ca.crt"], "-----BEGIN CERTIFICATE-----"), "the roots of the failover target are published too")
+	assert.Contains(t, <-recorder.Events, "TrustBundlePublished")
+
+	_, err = r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events, "an unchanged trust bundle is not published again")
+	assert.Equal(t, 4, fake.Calls("FetchCaCerts"))
+
+	// An edited trust bundle is restored.
+	published := configMap.Data["ca.crt"]
+	configMap.Data["ca.crt"] = "tampered"
+	require.NoError(t, kubeClient.Update(t.Context(), &configMap))
+	_, err = r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	require.NoError(t, err)
+	require.NoError(t, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(&configMap), &configMap))
+	assert.Equal(t, published, configMap.Data["ca.crt"])
+	assert.Contains(t, <-recorder.Events, "TrustBundlePublished")
+
+	// So is a deleted one.
+	require.NoError(t, kubeClient.Delete(t.Context(), &configMap))
+	_, err = r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	require.NoError(t, err)
+	require.NoError(t, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(&configMap), &configMap))
+	assert.Equal(t, published, configMap.Data["ca.crt"])
+	assert.Contains(t, <-recorder.Events, "TrustBundlePublished")
+}
+
+func TestChartClusterRoleTrustBundles(t *testing.T) {
+	configMapVerbs := []string{"get", "list", "watch", "create", "patch"}
+
+	role := chartClusterRole(t, nil)
+	for _, verb := range configMapVerbs {
+		assert.False(t, roleAllows(role, verb, "", "configmaps", "my-pool-roots"), verb)
+	}
+
+	role = chartClusterRole(t, map[string]any{"trustBundles": map[string]any{"enabled": true, "namespace": ""}})
+	for _, verb := range configMapVerbs {
+		assert.True(t, roleAllows(role, verb, "", "configmaps", "my-pool-roots"), verb)
+	}
+	// Setting an owner reference that blocks the deletion of the issuer
+	// needs update on its finalizers, when OwnerReferencesPermissionEnforcement
+	// is enabled.
+	assert.True(t, roleAllows(role, "update", issuersv1beta1.GroupVersion.Group, "googlecasissuers/finalizers", "issuer"))
+	assert.True(t, roleAllows(role, "update", issuersv1beta1.GroupVersion.Group, "googlecasclusterissuers/finalizers", "issuer"))
+
+	// With a trust bundle namespace, ConfigMaps are only accessible through
+	// a Role in that namespace.
+	role = chartClusterRole(t, map[string]any{"trustBundles": map[string]any{"enabled": true, "namespace": "cert-manager"}})
+	for _, verb := range configMapVerbs {
+		assert.False(t, roleAllows(role, verb, "", "configmaps", "my-pool-roots"), verb)
+	}
+	assert.True(t, roleAllows(role, "update", issuersv1beta1.GroupVersion.Group, "googlecasclusterissuers/finalizers", "issuer"))
+}
+
+func TestTrustBundleControllerNamespace(t *testing.T) {
+	fake, cas, spec := newFakeCAS(t)
+	cas.TrustBundleNamespace = "cert-manager"
+	spec.TrustBundle = &issuersv1beta1.TrustBundle{ConfigMapName: "roots"}
+	kubeClient := cas.client
+
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns", UID: "issuer-uid"}, Spec: *spec}
+	require.NoError(t, kubeClient.Create(t.Context(), issuer))
+
+	recorder := &events.FakeRecorder{Events: make(chan string, 10)}
+	r := &trustBundleController{
+		cas:       cas,
+		client:    kubeClient,
+		reader:    kubeClient,
+		recorder:  recorder,
+		newIssuer: func() issuerapi.Issuer { return &issuersv1beta1.GoogleCASIssuer{} },
+	}
+	_, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	assert.ErrorContains(t, err, "ConfigMap ns/roots is not in namespace cert-manager")
+	assert.Contains(t, <-recorder.Events, "TrustBundleFailed")
+	assert.Zero(t, fake.Calls("FetchCaCerts"))
+
+	err = kubeClient.Get(t.Context(), types.NamespacedName{Namespace: "ns", Name: "roots"}, &corev1.ConfigMap{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestTrustBundleControllerForeignConfigMap(t *testing.T) {
+	_, cas, spec := newFakeCAS(t)
+	spec.TrustBundle = &issuersv1beta1.TrustBundle{ConfigMapName: "roots", Key: "roots.pem"}
+	kubeClient := cas.client
+
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer", Namespace: "ns", UID: "issuer-uid"}, Spec: *spec}
+	foreign := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "roots", Namespace: "ns"}, Data: map[string]string{"roots.pem": "mine"}}
+	require.NoError(t, kubeClient.Create(t.Context(), issuer))
+	require.NoError(t, kubeClient.Create(t.Context(), foreign))
+
+	recorder := &events.FakeRecorder{Events: make(chan string, 10)}
+	r := &trustBundleController{
+		cas:       cas,
+		client:    kubeClient,
+		reader:    kubeClient,
+		recorder:  recorder,
+		newIssuer: func() issuerapi.Issuer { return &issuersv1beta1.GoogleCASIssuer{} },
+	}
+	_, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(issuer)})
+	assert.ErrorContains(t, err, "ConfigMap ns/roots already exists and is not owned by the issuer")
+	assert.Contains(t, <-recorder.Events, "TrustBundleFailed")
+
+	require.NoError(t, kubeClient.Get(t.Context(), client.ObjectKeyFromObject(foreign), foreign))
+	assert.Equal(t, "mine", foreign.Data["roots.pem"])
+}
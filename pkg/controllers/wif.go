@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google/externalaccount"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+const (
+	// serviceAccountTokenExpiration is the lifetime requested for the
+	// ServiceAccount tokens exchanged through STS.
+	serviceAccountTokenExpiration = time.Hour
+
+	jwtTokenType = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// tokenAudience returns the audience of the ServiceAccount tokens requested for
+// config.
+func tokenAudience(config *issuersv1beta1.WorkloadIdentityFederationConfig) string {
+	if config.TokenAudience != "" {
+		return config.TokenAudience
+	}
+	return "https:" + config.Audience
+}
+
+// workloadIdentityKey identifies a Workload Identity Federation config in a
+// casClientKey.
+func workloadIdentityKey(namespace string, config *issuersv1beta1.WorkloadIdentityFederationConfig) string {
+	return strings.Join([]string{namespace, config.ServiceAccountName, config.Audience, tokenAudience(config)}, "|")
+}
+
+// workloadIdentityTokenSource returns a token source that exchanges tokens for
+// the configured Kubernetes ServiceAccount through the Google Security Token
+// Service.
+func workloadIdentityTokenSource(ctx context.Context, kubeClient client.Client, namespace string, config *issuersv1beta1.WorkloadIdentityFederationConfig) (oauth2.TokenSource, error) {
+	return externalaccount.NewTokenSource(ctx, externalaccount.Config{
+		Audience:         config.Audience,
+		SubjectTokenType: jwtTokenType,
+		Scopes:           defaultImpersonationScopes,
+		SubjectTokenSupplier: &serviceAccountTokenSupplier{
+			client:    kubeClient,
+			namespace: namespace,
+			name:      config.ServiceAccountName,
+			audience:  tokenAudience(config),
+			now:       time.Now,
+		},
+	})
+}
+
+// serviceAccountTokenSupplier requests ServiceAccount tokens through the
+// TokenRequest API. The external account token source doesn't cache subject
+// tokens, so a token is reused until most of its lifetime has passed.
+type serviceAccountTokenSupplier struct {
+	client    client.Client
+	namespace string
+	name      string
+	audience  string
+	now       func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+var _ externalaccount.SubjectTokenSupplier = &serviceAccountTokenSupplier{}
+
+func (s *serviceAccountTokenSupplier) SubjectToken(ctx context.Context, _ externalaccount.SupplierOptions) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace},
+	}
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         []string{s.audience},
+			ExpirationSeconds: ptr.To(int64(serviceAccountTokenExpiration.Seconds())),
+		},
+	}
+	if err := s.client.SubResource("token").Create(ctx, serviceAccount, tokenRequest); err != nil {
+		return "", fmt.Errorf("failed to request a token for ServiceAccount %s/%s: %w", s.namespace, s.name, err)
+	}
+
+	s.token = tokenRequest.Status.Token
+	// Refresh once 80% of the token's lifetime has passed.
+	lifetime := tokenRequest.Status.ExpirationTimestamp.Sub(now)
+	s.refreshAt = now.Add(lifetime * 4 / 5)
+	return s.token, nil
+}
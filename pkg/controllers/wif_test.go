@@ -0,0 +1,103 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2/google/externalaccount"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func TestTokenAudience(t *testing.T) {
+	const provider = "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/cluster"
+
+	assert.Equal(t, "https://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/cluster",
+		tokenAudience(&issuersv1beta1.WorkloadIdentityFederationConfig{Audience: provider}))
+	assert.Equal(t, "cas-issuer",
+		tokenAudience(&issuersv1beta1.WorkloadIdentityFederationConfig{Audience: provider, TokenAudience: "cas-issuer"}))
+}
+
+func TestServiceAccountTokenSupplier(t *testing.T) {
+	now := time.Now()
+
+	var requests []*authenticationv1.TokenRequest
+	kubeClient := fake.NewClientBuilder().
+		WithObjects(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "cas-issuer", Namespace: "tenant-a"}}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+				tokenRequest := subResource.(*authenticationv1.TokenRequest)
+				requests = append(requests, tokenRequest.DeepCopy())
+				if err := c.SubResource(subResourceName).Create(ctx, obj, subResource, opts...); err != nil {
+					return err
+				}
+				tokenRequest.Status.Token = fmt.Sprintf("token-%d", len(requests))
+				tokenRequest.Status.ExpirationTimestamp = metav1.NewTime(now.Add(time.Hour))
+				return nil
+			},
+		}).
+		Build()
+
+	supplier := &serviceAccountTokenSupplier{
+		client:    kubeClient,
+		namespace: "tenant-a",
+		name:      "cas-issuer",
+		audience:  "https://iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/cluster",
+		now:       func() time.Time { return now },
+	}
+
+	token, err := supplier.SubjectToken(t.Context(), externalaccount.SupplierOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{supplier.audience}, requests[0].Spec.Audiences)
+
+	now = now.Add(30 * time.Minute)
+	token, err = supplier.SubjectToken(t.Context(), externalaccount.SupplierOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token, "tokens are reused until most of their lifetime has passed")
+
+	now = now.Add(20 * time.Minute)
+	token, err = supplier.SubjectToken(t.Context(), externalaccount.SupplierOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	supplier.name = "missing"
+	supplier.token = ""
+	_, err = supplier.SubjectToken(t.Context(), externalaccount.SupplierOptions{})
+	assert.ErrorContains(t, err, "failed to request a token for ServiceAccount tenant-a/missing")
+}
+
+func TestChartClusterRoleServiceAccountTokens(t *testing.T) {
+	assert.False(t, roleAllows(chartClusterRole(t, nil), "create", "", "serviceaccounts/token", "cas-issuer"),
+		"tokens can only be created with Workload Identity Federation enabled")
+
+	role := chartClusterRole(t, map[string]any{"workloadIdentityFederation": map[string]any{"enabled": true}})
+	assert.True(t, roleAllows(role, "create", "", "serviceaccounts/token", "cas-issuer"))
+}
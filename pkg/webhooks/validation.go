@@ -0,0 +1,272 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"maps"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+var (
+	// projectIDRegexp matches Google Cloud project IDs, optionally prefixed
+	// with the domain of a domain-scoped project, and project numbers.
+	projectIDRegexp = regexp.MustCompile(`^(?:(?:[a-z0-9][a-z0-9.-]*[a-z0-9]:)?[a-z][a-z0-9-]{4,28}[a-z0-9]|[0-9]+)$`)
+
+	// locationRegexp matches Google Cloud regions, e.g. us-east1.
+	locationRegexp = regexp.MustCompile(`^[a-z]+(?:-[a-z]+)*[0-9]+$`)
+
+	// resourceIDRegexp matches the IDs CAS allows for CA pools, certificate
+	// authorities and certificate templates.
+	resourceIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,63}$`)
+
+	// labelKeyRegexp matches the label keys CAS allows on certificates.
+	labelKeyRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+
+	// serviceAccountEmailRegexp loosely matches Google service account emails.
+	serviceAccountEmailRegexp = regexp.MustCompile(`^[^@\s/]+@[^@\s/]+$`)
+)
+
+// SetDefaultsGoogleCASIssuerSpec sets the defaults of an issuer spec.
+func SetDefaultsGoogleCASIssuerSpec(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+	if spec.CAFetchMode == "" {
+		spec.CAFetchMode = issuersv1beta1.CAFetchModeCA
+	}
+}
+
+// ValidateGoogleCASIssuerSpec validates an issuer spec, so that mistakes that
+// would otherwise only show up once the issuer is checked are rejected when
+// the issuer is applied.
+func ValidateGoogleCASIssuerSpec(spec *issuersv1beta1.GoogleCASIssuerSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	errs = append(errs, validateProject(spec.Project, fldPath.Child("project"))...)
+	errs = append(errs, validateLocation(spec.Location, fldPath.Child("location"))...)
+	errs = append(errs, validateResourceID(spec.CaPoolId, fldPath.Child("caPoolId"), true)...)
+	errs = append(errs, validateResourceID(spec.CertificateAuthorityId, fldPath.Child("certificateAuthorityId"), false)...)
+
+	pools := []string{spec.Project + "/" + spec.Location + "/" + spec.CaPoolId}
+	for i, target := range spec.FailoverTargets {
+		targetPath := fldPath.Child("failoverTargets").Index(i)
+		errs = append(errs, validateProject(target.Project, targetPath.Child("project"))...)
+		errs = append(errs, validateLocation(target.Location, targetPath.Child("location"))...)
+		errs = append(errs, validateResourceID(target.CaPoolId, targetPath.Child("caPoolId"), true)...)
+		errs = append(errs, validateResourceID(target.CertificateAuthorityId, targetPath.Child("certificateAuthorityId"), false)...)
+		pool := target.Project + "/" + target.Location + "/" + target.CaPoolId
+		if slices.Contains(pools, pool) {
+			errs = append(errs, field.Duplicate(targetPath, pool))
+		}
+		pools = append(pools, pool)
+	}
+
+	if spec.CertificateTemplate != "" {
+		errs = append(errs, validateCertificateTemplate(spec.CertificateTemplate, fldPath.Child("certificateTemplate"))...)
+	}
+	for i, template := range spec.AllowedCertificateTemplates {
+		errs = append(errs, validateCertificateTemplate(template, fldPath.Child("allowedCertificateTemplates").Index(i))...)
+	}
+	errs = append(errs, validateCertificateLabels(spec.CertificateLabels, fldPath.Child("certificateLabels"))...)
+
+	credentialsPath := fldPath.Child("credentials")
+	switch {
+	case spec.Credentials.Name != "" && spec.Credentials.Key == "":
+		errs = append(errs, field.Required(credentialsPath.Child("key"), "must be set together with credentials.name"))
+	case spec.Credentials.Name == "" && spec.Credentials.Key != "":
+		errs = append(errs, field.Required(credentialsPath.Child("name"), "must be set together with credentials.key"))
+	}
+
+	if wif := spec.WorkloadIdentityFederation; wif != nil {
+		wifPath := fldPath.Child("workloadIdentityFederation")
+		if spec.Credentials.Name != "" || spec.Credentials.Key != "" {
+			errs = append(errs, field.Forbidden(wifPath, "credentials and workloadIdentityFederation are mutually exclusive"))
+		}
+		if wif.ServiceAccountName == "" {
+			errs = append(errs, field.Required(wifPath.Child("serviceAccountName"), ""))
+		}
+		if !strings.HasPrefix(wif.Audience, "//iam.googleapis.com/") {
+			errs = append(errs, field.Invalid(wifPath.Child("audience"), wif.Audience,
+				"must be the full resource name of a workload identity pool provider, starting with //iam.googleapis.com/"))
+		}
+	}
+
+	if impersonate := spec.Impersonate; impersonate != nil {
+		impersonatePath := fldPath.Child("impersonate")
+		if !serviceAccountEmailRegexp.MatchString(impersonate.TargetPrincipal) {
+			errs = append(errs, field.Invalid(impersonatePath.Child("targetPrincipal"), impersonate.TargetPrincipal, "must be a service account email address"))
+		}
+		for i, delegate := range impersonate.Delegates {
+			if !serviceAccountEmailRegexp.MatchString(delegate) {
+				errs = append(errs, field.Invalid(impersonatePath.Child("delegates").Index(i), delegate, "must be a service account email address"))
+			}
+		}
+	}
+
+	if spec.Policy != nil {
+		errs = append(errs, validatePolicy(spec.Policy, fldPath.Child("policy"))...)
+	}
+
+	if spec.TrustBundle != nil {
+		errs = append(errs, validateTrustBundle(spec.TrustBundle, fldPath.Child("trustBundle"))...)
+	}
+
+	if revocation := spec.Revocation; revocation != nil {
+		revocationPath := fldPath.Child("revocation")
+		if len(revocation.On) == 0 {
+			errs = append(errs, field.Required(revocationPath.Child("on"), ""))
+		}
+		if revocation.GracePeriod != nil {
+			gracePeriodPath := revocationPath.Child("gracePeriod")
+			switch {
+			case revocation.GracePeriod.Duration < 0:
+				errs = append(errs, field.Invalid(gracePeriodPath, revocation.GracePeriod.Duration.String(), "must not be negative"))
+			case !slices.Contains(revocation.On, issuersv1beta1.RevokeOnSuperseded):
+				errs = append(errs, field.Forbidden(gracePeriodPath, "only applies to certificates revoked when Superseded"))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateProject(project string, fldPath *field.Path) field.ErrorList {
+	if project == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	if !projectIDRegexp.MatchString(project) {
+		return field.ErrorList{field.Invalid(fldPath, project, "must be a Google Cloud project ID or number")}
+	}
+	return nil
+}
+
+func validateLocation(location string, fldPath *field.Path) field.ErrorList {
+	if location == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	if !locationRegexp.MatchString(location) {
+		return field.ErrorList{field.Invalid(fldPath, location, "must be a Google Cloud region, e.g. us-east1")}
+	}
+	return nil
+}
+
+func validateResourceID(id string, fldPath *field.Path, required bool) field.ErrorList {
+	if id == "" {
+		if required {
+			return field.ErrorList{field.Required(fldPath, "")}
+		}
+		return nil
+	}
+	if !resourceIDRegexp.MatchString(id) {
+		return field.ErrorList{field.Invalid(fldPath, id, "must be 1-63 letters, digits, hyphens or underscores")}
+	}
+	return nil
+}
+
+func validateCertificateTemplate(template string, fldPath *field.Path) field.ErrorList {
+	parts := strings.Split(template, "/")
+	if len(parts) != 6 || parts[0] != "projects" || parts[2] != "locations" || parts[4] != "certificateTemplates" ||
+		!projectIDRegexp.MatchString(parts[1]) ||
+		!locationRegexp.MatchString(parts[3]) ||
+		!resourceIDRegexp.MatchString(parts[5]) {
+		return field.ErrorList{field.Invalid(fldPath, template, "must be of the form projects/PROJECT/locations/LOCATION/certificateTemplates/TEMPLATE_ID")}
+	}
+	return nil
+}
+
+// validateCertificateLabels checks that labels have valid CAS label keys, and
+// that their values are valid templates.
+func validateCertificateLabels(labels map[string]string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		if !labelKeyRegexp.MatchString(key) {
+			errs = append(errs, field.Invalid(fldPath.Key(key), key, "must start with a lowercase letter, and only contain lowercase letters, digits, '_' and '-', at most 63 characters"))
+		}
+		if _, err := template.New(key).Parse(labels[key]); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Key(key), labels[key], err.Error()))
+		}
+	}
+	return errs
+}
+
+// validatePolicy checks that the patterns and ranges of an issuance policy
+// can match requests.
+func validatePolicy(policy *issuersv1beta1.IssuancePolicy, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, patterns := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"allowedDNSNames", policy.AllowedDNSNames},
+		{"allowedURIs", policy.AllowedURIs},
+		{"allowedEmailAddresses", policy.AllowedEmailAddresses},
+	} {
+		for i, pattern := range patterns.patterns {
+			if pattern == "" {
+				errs = append(errs, field.Invalid(fldPath.Child(patterns.name).Index(i), pattern, "must not be empty"))
+			}
+		}
+	}
+	for i, cidr := range policy.AllowedIPRanges {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("allowedIPRanges").Index(i), cidr, "must be a CIDR range, e.g. 10.0.0.0/8"))
+		}
+	}
+	for i, key := range policy.AllowedPrivateKeys {
+		minSizePath := fldPath.Child("allowedPrivateKeys").Index(i).Child("minSize")
+		switch {
+		case key.MinSize < 0:
+			errs = append(errs, field.Invalid(minSizePath, key.MinSize, "must not be negative"))
+		case key.MinSize > 0 && key.Algorithm == cmapi.Ed25519KeyAlgorithm:
+			errs = append(errs, field.Forbidden(minSizePath, "Ed25519 keys have a fixed size"))
+		}
+	}
+	if policy.MaxDuration != nil && policy.MaxDuration.Duration <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("maxDuration"), policy.MaxDuration.Duration.String(), "must be positive"))
+	}
+	return errs
+}
+
+// validateTrustBundle checks the ConfigMap a trust bundle is published to.
+func validateTrustBundle(trustBundle *issuersv1beta1.TrustBundle, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(trustBundle.ConfigMapName) {
+		errs = append(errs, field.Invalid(fldPath.Child("configMapName"), trustBundle.ConfigMapName, msg))
+	}
+	if trustBundle.Key != "" {
+		for _, msg := range validation.IsConfigMapKey(trustBundle.Key) {
+			errs = append(errs, field.Invalid(fldPath.Child("key"), trustBundle.Key, msg))
+		}
+	}
+	if trustBundle.RefreshInterval != nil && trustBundle.RefreshInterval.Duration < time.Minute {
+		errs = append(errs, field.Invalid(fldPath.Child("refreshInterval"), trustBundle.RefreshInterval.Duration.String(), "must be at least 1m"))
+	}
+	if trustBundle.TrustManagerNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(trustBundle.TrustManagerNamespace) {
+			errs = append(errs, field.Invalid(fldPath.Child("trustManagerNamespace"), trustBundle.TrustManagerNamespace, msg))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+func validSpec() issuersv1beta1.GoogleCASIssuerSpec {
+	return issuersv1beta1.GoogleCASIssuerSpec{
+		Project:  "my-project-123",
+		Location: "us-east1",
+		CaPoolId: "my-pool",
+	}
+}
+
+func TestValidateGoogleCASIssuerSpec(t *testing.T) {
+	tests := map[string]struct {
+		mutate     func(spec *issuersv1beta1.GoogleCASIssuerSpec)
+		wantFields []string
+	}{
+		"valid": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {},
+		},
+		"valid with everything set": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Project = "example.com:my-project"
+				spec.Location = "northamerica-northeast1"
+				spec.CertificateAuthorityId = "my_ca-1"
+				spec.CertificateTemplate = "projects/123456/locations/us-east1/certificateTemplates/leaf"
+				spec.Credentials = cmmetav1.SecretKeySelector{LocalObjectReference: cmmetav1.LocalObjectReference{Name: "sa"}, Key: "key.json"}
+				spec.Impersonate = &issuersv1beta1.ImpersonationConfig{
+					TargetPrincipal: "tenant@my-project.iam.gserviceaccount.com",
+					Delegates:       []string{"delegate@my-project.iam.gserviceaccount.com"},
+				}
+				spec.Revocation = &issuersv1beta1.RevocationPolicy{
+					On:          []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnCertificateDeleted, issuersv1beta1.RevokeOnSuperseded},
+					GracePeriod: &metav1.Duration{Duration: time.Hour},
+					Reason:      issuersv1beta1.RevocationReasonSuperseded,
+				}
+				spec.CertificateLabels = map[string]string{
+					"team":      "payments",
+					"namespace": "{{ .Namespace }}",
+				}
+				spec.Policy = &issuersv1beta1.IssuancePolicy{
+					AllowedDNSNames:    []string{"*.example.com"},
+					AllowedIPRanges:    []string{"10.0.0.0/8", "fd00::/8"},
+					AllowedPrivateKeys: []issuersv1beta1.PrivateKeyPolicy{{Algorithm: cmapi.RSAKeyAlgorithm, MinSize: 2048}, {Algorithm: cmapi.Ed25519KeyAlgorithm}},
+					MaxDuration:        &metav1.Duration{Duration: 90 * 24 * time.Hour},
+				}
+			},
+		},
+		"invalid allowed certificate template": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.AllowedCertificateTemplates = []string{
+					"projects/my-project/locations/us-east1/certificateTemplates/client",
+					"client",
+				}
+			},
+			wantFields: []string{"spec.allowedCertificateTemplates[1]"},
+		},
+		"invalid failover targets": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.FailoverTargets = []issuersv1beta1.CAPoolTarget{
+					{Project: "my-project", Location: "europe-west1", CaPoolId: "my-pool"},
+					{Project: spec.Project, Location: spec.Location, CaPoolId: spec.CaPoolId},
+					{Project: "my-project", Location: "europe", CaPoolId: "my-pool"},
+				}
+			},
+			wantFields: []string{"spec.failoverTargets[1]", "spec.failoverTargets[2].location"},
+		},
+		"invalid certificate labels": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.CertificateLabels = map[string]string{
+					"Team":      "payments",
+					"namespace": "{{ .Namespace",
+				}
+			},
+			wantFields: []string{"spec.certificateLabels[Team]", "spec.certificateLabels[namespace]"},
+		},
+		"missing pool location and project": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				*spec = issuersv1beta1.GoogleCASIssuerSpec{}
+			},
+			wantFields: []string{"spec.project", "spec.location", "spec.caPoolId"},
+		},
+		"malformed IDs": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Project = "My_Project"
+				spec.Location = "us east"
+				spec.CaPoolId = "projects/p/locations/l/caPools/pool"
+				spec.CertificateAuthorityId = "ca.1"
+			},
+			wantFields: []string{"spec.project", "spec.location", "spec.caPoolId", "spec.certificateAuthorityId"},
+		},
+		"template ID instead of resource name": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.CertificateTemplate = "leaf"
+			},
+			wantFields: []string{"spec.certificateTemplate"},
+		},
+		"credentials name without key": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Credentials.Name = "sa"
+			},
+			wantFields: []string{"spec.credentials.key"},
+		},
+		"credentials key without name": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Credentials.Key = "key.json"
+			},
+			wantFields: []string{"spec.credentials.name"},
+		},
+		"workload identity federation with credentials": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Credentials = cmmetav1.SecretKeySelector{LocalObjectReference: cmmetav1.LocalObjectReference{Name: "sa"}, Key: "key.json"}
+				spec.WorkloadIdentityFederation = &issuersv1beta1.WorkloadIdentityFederationConfig{
+					ServiceAccountName: "cas-issuer",
+					Audience:           "projects/123/locations/global/workloadIdentityPools/pool/providers/cluster",
+				}
+			},
+			wantFields: []string{"spec.workloadIdentityFederation", "spec.workloadIdentityFederation.audience"},
+		},
+		"impersonating something that is not a service account": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Impersonate = &issuersv1beta1.ImpersonationConfig{
+					TargetPrincipal: "projects/-/serviceAccounts/tenant@my-project.iam.gserviceaccount.com",
+					Delegates:       []string{"delegate"},
+				}
+			},
+			wantFields: []string{"spec.impersonate.targetPrincipal", "spec.impersonate.delegates[0]"},
+		},
+		"revocation grace period without superseded": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Revocation = &issuersv1beta1.RevocationPolicy{
+					On:          []issuersv1beta1.RevocationTrigger{issuersv1beta1.RevokeOnCertificateRequestDeleted},
+					GracePeriod: &metav1.Duration{Duration: time.Hour},
+				}
+			},
+			wantFields: []string{"spec.revocation.gracePeriod"},
+		},
+		"invalid policy": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Policy = &issuersv1beta1.IssuancePolicy{
+					AllowedURIs:        []string{""},
+					AllowedIPRanges:    []string{"10.0.0.1"},
+					AllowedPrivateKeys: []issuersv1beta1.PrivateKeyPolicy{{Algorithm: cmapi.ECDSAKeyAlgorithm, MinSize: -1}, {Algorithm: cmapi.Ed25519KeyAlgorithm, MinSize: 256}},
+					MaxDuration:        &metav1.Duration{},
+				}
+			},
+			wantFields: []string{
+				"spec.policy.allowedURIs[0]",
+				"spec.policy.allowedIPRanges[0]",
+				"spec.policy.allowedPrivateKeys[0].minSize",
+				"spec.policy.allowedPrivateKeys[1].minSize",
+				"spec.policy.maxDuration",
+			},
+		},
+		"invalid trust bundle": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.TrustBundle = &issuersv1beta1.TrustBundle{
+					ConfigMapName:         "Roots",
+					Key:                   "ca/crt",
+					RefreshInterval:       &metav1.Duration{Duration: time.Second},
+					TrustManagerNamespace: "cert_manager",
+				}
+			},
+			wantFields: []string{
+				"spec.trustBundle.configMapName",
+				"spec.trustBundle.key",
+				"spec.trustBundle.refreshInterval",
+				"spec.trustBundle.trustManagerNamespace",
+			},
+		},
+		"revocation without triggers": {
+			mutate: func(spec *issuersv1beta1.GoogleCASIssuerSpec) {
+				spec.Revocation = &issuersv1beta1.RevocationPolicy{}
+			},
+			wantFields: []string{"spec.revocation.on"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := validSpec()
+			tt.mutate(&spec)
+
+			var fields []string
+			for _, err := range ValidateGoogleCASIssuerSpec(&spec, field.NewPath("spec")) {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestIssuerWebhook(t *testing.T) {
+	w := issuerWebhook[*issuersv1beta1.GoogleCASIssuer]{
+		kind:       issuersv1beta1.GroupVersion.WithKind("GoogleCASIssuer").GroupKind(),
+		spec:       func(obj *issuersv1beta1.GoogleCASIssuer) *issuersv1beta1.GoogleCASIssuerSpec { return &obj.Spec },
+		namespaced: true,
+	}
+
+	issuer := &issuersv1beta1.GoogleCASIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer"}, Spec: validSpec()}
+	require.NoError(t, w.Default(t.Context(), issuer))
+	assert.Equal(t, issuersv1beta1.CAFetchModeCA, issuer.Spec.CAFetchMode)
+
+	issuer.Spec.CAFetchMode = issuersv1beta1.CAFetchModePoolCAs
+	require.NoError(t, w.Default(t.Context(), issuer))
+	assert.Equal(t, issuersv1beta1.CAFetchModePoolCAs, issuer.Spec.CAFetchMode, "an explicit mode must be kept")
+
+	_, err := w.ValidateCreate(t.Context(), issuer)
+	require.NoError(t, err)
+
+	invalid := issuer.DeepCopy()
+	invalid.Spec.CaPoolId = ""
+	_, err = w.ValidateUpdate(t.Context(), issuer, invalid)
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.ErrorContains(t, err, `GoogleCASIssuer.cas-issuer.jetstack.io "issuer" is invalid: spec.caPoolId: Required value`)
+
+	trustManager := issuer.DeepCopy()
+	trustManager.Spec.TrustBundle = &issuersv1beta1.TrustBundle{ConfigMapName: "roots", TrustManagerNamespace: "cert-manager"}
+	_, err = w.ValidateCreate(t.Context(), trustManager)
+	assert.ErrorContains(t, err, "spec.trustBundle.trustManagerNamespace: Forbidden")
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhooks implements the validating and defaulting admission
+// webhooks for GoogleCASIssuer and GoogleCASClusterIssuer resources.
+package webhooks
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	issuersv1beta1 "github.com/cert-manager/google-cas-issuer/api/v1beta1"
+)
+
+// SetupWithManager registers the webhooks for both issuer kinds with the
+// manager's webhook server.
+func SetupWithManager(mgr ctrl.Manager) error {
+	issuer := issuerWebhook[*issuersv1beta1.GoogleCASIssuer]{
+		kind:       issuersv1beta1.GroupVersion.WithKind("GoogleCASIssuer").GroupKind(),
+		spec:       func(obj *issuersv1beta1.GoogleCASIssuer) *issuersv1beta1.GoogleCASIssuerSpec { return &obj.Spec },
+		namespaced: true,
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr, &issuersv1beta1.GoogleCASIssuer{}).
+		WithDefaulter(issuer).
+		WithValidator(issuer).
+		Complete(); err != nil {
+		return err
+	}
+
+	clusterIssuer := issuerWebhook[*issuersv1beta1.GoogleCASClusterIssuer]{
+		kind: issuersv1beta1.GroupVersion.WithKind("GoogleCASClusterIssuer").GroupKind(),
+		spec: func(obj *issuersv1beta1.GoogleCASClusterIssuer) *issuersv1beta1.GoogleCASIssuerSpec { return &obj.Spec },
+	}
+	return ctrl.NewWebhookManagedBy(mgr, &issuersv1beta1.GoogleCASClusterIssuer{}).
+		WithDefaulter(clusterIssuer).
+		WithValidator(clusterIssuer).
+		Complete()
+}
+
+// issuerWebhook defaults and validates the spec of an issuer of type T.
+type issuerWebhook[T client.Object] struct {
+	kind schema.GroupKind
+	spec func(T) *issuersv1beta1.GoogleCASIssuerSpec
+	// namespaced is set for GoogleCASIssuers, which may not use the settings
+	// that reach outside their namespace.
+	namespaced bool
+}
+
+var (
+	_ admission.Defaulter[*issuersv1beta1.GoogleCASIssuer] = issuerWebhook[*issuersv1beta1.GoogleCASIssuer]{}
+	_ admission.Validator[*issuersv1beta1.GoogleCASIssuer] = issuerWebhook[*issuersv1beta1.GoogleCASIssuer]{}
+)
+
+func (w issuerWebhook[T]) Default(_ context.Context, obj T) error {
+	SetDefaultsGoogleCASIssuerSpec(w.spec(obj))
+	return nil
+}
+
+func (w issuerWebhook[T]) ValidateCreate(_ context.Context, obj T) (admission.Warnings, error) {
+	return nil, w.validate(obj)
+}
+
+func (w issuerWebhook[T]) ValidateUpdate(_ context.Context, _, newObj T) (admission.Warnings, error) {
+	return nil, w.validate(newObj)
+}
+
+func (w issuerWebhook[T]) ValidateDelete(context.Context, T) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w issuerWebhook[T]) validate(obj T) error {
+	spec := w.spec(obj)
+	errs := ValidateGoogleCASIssuerSpec(spec, field.NewPath("spec"))
+	if w.namespaced && spec.TrustBundle != nil && spec.TrustBundle.TrustManagerNamespace != "" {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "trustBundle", "trustManagerNamespace"), "only GoogleCASClusterIssuers may publish into another namespace"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(w.kind, obj.GetName(), errs)
+}